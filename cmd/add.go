@@ -0,0 +1,177 @@
+/*
+Copyright © 2021 Sankul Rawat sankul.rawat.28@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dark-shade/go-setup/pkg/gomod"
+	"github.com/dark-shade/go-setup/pkg/scaffold"
+	"github.com/dark-shade/go-setup/pkg/task"
+	"github.com/dark-shade/go-setup/pkg/utils"
+	"github.com/dark-shade/go-setup/pkg/vfs"
+	"github.com/spf13/cobra"
+)
+
+var (
+	addLocation string
+	addParent   string
+)
+
+// addCmd represents the add command
+var addCmd = &cobra.Command{
+	Use:   "add <kind> <name> | add init <system> <binary>",
+	Short: "Adds a component to an existing project",
+	Long: `Adds a component to the existing project containing the location, whose module path is read from go.mod.
+
+Kinds of components:
+` + componentsHelp() + `
+The binary of an init system configuration is a main package of cmd/. The configurations are checked by
+the init-config rule of go-setup lint.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 0 && args[0] == "init" {
+			return cobra.ExactArgs(3)(cmd, args)
+		}
+		return cobra.ExactArgs(2)(cmd, args)
+	},
+	ValidArgs: componentKinds(),
+	Run: func(cmd *cobra.Command, args []string) {
+		kind, name := args[0], args[1]
+
+		root, err := gomod.FindRoot(vfs.OS(), addLocation)
+		if err != nil {
+			utils.CheckErrFatal(err)
+		}
+
+		mod, err := gomod.Read(vfs.OS(), root)
+		if err != nil {
+			utils.CheckErrFatal(err)
+		}
+
+		opts := scaffold.Options{Location: root, ModulePath: mod.Module}
+
+		// commands are also registered with their parent
+		var command *scaffold.CommandData
+		var gen *scaffold.Generator
+		switch kind {
+		case "init":
+			if err := checkBinary(root, args[2]); err != nil {
+				utils.CheckErrFatal(err)
+			}
+			gen, err = scaffold.NewInit(opts, args[1], args[2])
+			if err != nil {
+				utils.CheckErrFatal(err)
+			}
+		case "command":
+			data, err := scaffold.NewCommandData(name, addParent)
+			if err != nil {
+				utils.CheckErrFatal(err)
+			}
+			// the parent is checked first, so no command file is left without a parent
+			if _, err := scaffold.ParentFile(vfs.BasePath(vfs.OS(), root), data); err != nil {
+				utils.CheckErrFatal(err)
+			}
+			command = &data
+			gen = scaffold.NewCommand(opts, data)
+		default:
+			gen, err = scaffold.NewComponent(opts, kind, name)
+			if err != nil {
+				utils.CheckErrFatal(err)
+			}
+		}
+
+		_, res, err := gen.Generate()
+		if err != nil {
+			utils.CheckErrFatal(err)
+		}
+
+		for _, err := range res.Errors {
+			utils.CheckErrNonFatal(err)
+		}
+
+		for _, p := range res.Created {
+			fmt.Fprintln(cmd.OutOrStdout(), "Created: "+p)
+		}
+
+		if command != nil && len(res.Errors) == 0 {
+			fsys := vfs.BasePath(vfs.OS(), root)
+			edited, err := scaffold.RegisterCommand(fsys, *command)
+			if err != nil {
+				// an unregistered command breaks the tests of cmd, so its files are removed
+				for i := len(res.Created) - 1; i >= 0; i-- {
+					if err := fsys.Remove(res.Created[i]); err != nil {
+						utils.CheckErrNonFatal(err)
+					}
+				}
+				utils.CheckErrFatal(err)
+			}
+
+			if edited != "" {
+				fmt.Fprintf(cmd.OutOrStdout(), "Registered %s with %s in %s\n", command.Var, command.ParentVar, edited)
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(addCmd)
+
+	// local flags for addCmd
+	addCmd.Flags().StringVarP(&addLocation, "location", "l", ".", "location inside the project, go.mod is searched from there upwards")
+	addCmd.Flags().StringVar(&addParent, "parent", "", "path of the parent of a command, e.g. config (default is the command path without its last element, or root)")
+}
+
+// componentKinds returns the kinds of components that can be added
+func componentKinds() []string {
+	var kinds []string
+	for _, c := range scaffold.Components() {
+		kinds = append(kinds, c.Kind)
+	}
+	return kinds
+}
+
+// componentsHelp returns the help lines describing the kinds of components
+func componentsHelp() string {
+	var b strings.Builder
+	for _, c := range scaffold.Components() {
+		fmt.Fprintf(&b, "  %-12s %s\n", c.Kind, c.Description)
+	}
+	return b.String()
+}
+
+// checkBinary returns an error when cmd/<binary> of the project in root is not a main package
+func checkBinary(root, binary string) error {
+	cfg, err := task.Detect(vfs.BasePath(vfs.OS(), root))
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, b := range cfg.Binaries {
+		if b.Package == "./cmd/"+binary {
+			return nil
+		}
+		names = append(names, b.Name)
+	}
+	return fmt.Errorf("cmd/%s is not a main package of the project, binaries are: %s", binary, strings.Join(names, ", "))
+}
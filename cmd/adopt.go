@@ -0,0 +1,120 @@
+/*
+Copyright © 2021 Sankul Rawat sankul.rawat.28@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/dark-shade/go-setup/pkg/adopt"
+	"github.com/dark-shade/go-setup/pkg/gomod"
+	"github.com/dark-shade/go-setup/pkg/utils"
+	"github.com/dark-shade/go-setup/pkg/vfs"
+	"github.com/spf13/cobra"
+)
+
+var (
+	adoptLocation string
+	adoptKeep     []string
+	adoptDryRun   bool
+	adoptNoBuild  bool
+)
+
+// adoptCmd represents the adopt command
+var adoptCmd = &cobra.Command{
+	Use:   "adopt",
+	Short: "Migrates an existing project to the standard layout",
+	Long: `Analyzes the packages of the project containing the location and moves them to the standard layout:
+  - the main package of the root goes to cmd/<binary>, <binary> being the last element of the module path
+  - main packages of other top level directories go to cmd/
+  - other packages of top level directories which are not part of the layout go to internal/
+  - shell scripts of the root go to scripts/
+
+The plan is printed, then the files are moved, the imports of the moved packages are rewritten, the paths
+of the Makefile and Dockerfile are updated and go build ./... verifies the project still builds. When a file
+cannot be parsed nothing is moved, and when a step or the build fails the adoption is reverted.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		out := cmd.OutOrStdout()
+
+		root, err := gomod.FindRoot(vfs.OS(), adoptLocation)
+		if err != nil {
+			utils.CheckErrFatal(err)
+		}
+		fsys := vfs.BasePath(vfs.OS(), root)
+
+		plan, err := adopt.Analyze(fsys, adoptKeep)
+		if err != nil {
+			utils.CheckErrFatal(err)
+		}
+
+		if len(plan.Moves) == 0 {
+			fmt.Fprintln(out, root+" already follows the standard layout")
+			return
+		}
+
+		for _, m := range plan.Moves {
+			from, to := m.From, m.To
+			if m.Dir {
+				from, to = from+"/", to+"/"
+			}
+			fmt.Fprintf(out, "Move: %s -> %s (%s)\n", from, to, m.Reason)
+		}
+
+		if adoptDryRun {
+			fmt.Fprintf(out, "Finished dry run, would move %d paths\n", len(plan.Moves))
+			return
+		}
+
+		res, err := adopt.Apply(fsys, plan)
+		if err != nil {
+			utils.CheckErrFatal(err)
+		}
+
+		for _, p := range res.Rewritten {
+			fmt.Fprintln(out, "Rewrote imports: "+p)
+		}
+		for _, p := range res.Updated {
+			fmt.Fprintln(out, "Updated paths: "+p)
+		}
+		fmt.Fprintf(out, "Moved %d paths\n", len(plan.Moves))
+
+		if !adoptNoBuild {
+			if err := adopt.Build(root); err != nil {
+				if err := res.Revert(); err != nil {
+					utils.CheckErrNonFatal(err)
+				}
+				utils.CheckErrFatal(fmt.Errorf("the project does not build after the adoption, the adoption was reverted: %w", err))
+			}
+			fmt.Fprintln(out, "Verified the project builds with go build ./...")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(adoptCmd)
+
+	// local flags for adoptCmd
+	adoptCmd.Flags().StringVarP(&adoptLocation, "location", "l", ".", "location inside the project, go.mod is searched from there upwards")
+	adoptCmd.Flags().StringSliceVar(&adoptKeep, "keep", nil, "top level directories to leave where they are, e.g. public packages")
+	adoptCmd.Flags().BoolVar(&adoptDryRun, "dry-run", false, "prints the plan without moving anything")
+	adoptCmd.Flags().BoolVar(&adoptNoBuild, "no-build", false, "skips the go build ./... verification")
+}
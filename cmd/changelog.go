@@ -0,0 +1,261 @@
+/*
+Copyright © 2021 Sankul Rawat sankul.rawat.28@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dark-shade/go-setup/pkg/changelog"
+	"github.com/dark-shade/go-setup/pkg/gomod"
+	"github.com/dark-shade/go-setup/pkg/lint"
+	"github.com/dark-shade/go-setup/pkg/release"
+	"github.com/dark-shade/go-setup/pkg/semver"
+	"github.com/dark-shade/go-setup/pkg/utils"
+	"github.com/dark-shade/go-setup/pkg/vfs"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	changelogLocation string
+	changelogType     string
+	changelogFormat   string
+	changelogSince    string
+	changelogIssueURL string
+	changelogWrite    bool
+)
+
+// changelogCmd represents the changelog command
+var changelogCmd = &cobra.Command{
+	Use:   "changelog",
+	Short: "Manages the CHANGELOG.md of a project",
+}
+
+// changelogAddCmd represents the changelog add command
+var changelogAddCmd = &cobra.Command{
+	Use:   "add <message>",
+	Short: "Adds an entry to the unreleased changes of the changelog",
+	Long: `Adds the message as an entry of the change type section, e.g. ### Added, of the [Unreleased] section of the
+CHANGELOG.md of the project containing the location. The section is created when it does not exist.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		fsys := changelogFS()
+
+		data, err := vfs.ReadFile(fsys, changelog.File)
+		if err != nil {
+			utils.CheckErrFatal(err)
+		}
+
+		added, err := changelog.Add(data, changelogType, args[0])
+		if err != nil {
+			utils.CheckErrFatal(err)
+		}
+
+		if err := vfs.WriteFile(fsys, changelog.File, added, 0644); err != nil {
+			utils.CheckErrFatal(err)
+		}
+		for _, t := range changelog.Types {
+			if strings.EqualFold(t, changelogType) {
+				fmt.Fprintf(cmd.OutOrStdout(), "Added to ### %s of [Unreleased]: %s\n", t, args[0])
+			}
+		}
+	},
+}
+
+// changelogLintCmd represents the changelog lint command
+var changelogLintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Checks that the changelog follows Keep a Changelog",
+	Long: `Checks the CHANGELOG.md of the project containing the location against https://keepachangelog.com: the
+title, the [Unreleased] section followed by the versions, newest first, with their release dates, the change
+type sections, and the link definitions of the sections when the changelog has link definitions.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		data, err := vfs.ReadFile(changelogFS(), changelog.File)
+		if err != nil {
+			utils.CheckErrFatal(err)
+		}
+
+		var findings []lint.Finding
+		for _, p := range changelog.Lint(data, time.Now()) {
+			findings = append(findings, lint.Finding{Rule: "changelog", Severity: lint.Error, Path: changelog.File, Line: p.Line, Message: p.Message})
+		}
+
+		if err := lint.Write(cmd.OutOrStdout(), changelogFormat, findings); err != nil {
+			utils.CheckErrFatal(err)
+		}
+
+		if len(findings) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// changelogGenerateCmd represents the changelog generate command
+var changelogGenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generates changelog entries from conventional commits",
+	Long: `Generates the changelog entries of the conventional commits of the local git history since a tag, by
+default the latest one, see https://www.conventionalcommits.org. Features are Added, fixes Fixed, performance
+improvements and breaking changes Changed, the other commit types are left out. The issues referenced as #<id>
+are linked with the issue URL, where {id} is replaced by the issue number.
+
+The entries are printed, or added to the [Unreleased] section of CHANGELOG.md with --write, except the ones
+the section already lists. The version bump of the commits is suggested, major for breaking changes, minor for
+features and patch otherwise.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		out := cmd.OutOrStdout()
+		root := changelogRoot()
+		fsys := vfs.BasePath(vfs.OS(), root)
+
+		since := changelogSince
+		if !cmd.Flags().Changed("since") {
+			tag, err := changelog.LatestTag(root)
+			if err != nil {
+				utils.CheckErrFatal(err)
+			}
+			since = tag
+		}
+
+		commits, err := changelog.Commits(root, since)
+		if err != nil {
+			utils.CheckErrFatal(err)
+		}
+
+		issueURL := changelogIssueURL
+		if issueURL == "" {
+			issueURL = viper.GetString("issueURL")
+		}
+		if repo := release.RepoURL(fsys); issueURL == "" && repo != "" {
+			issueURL = repo + "/issues/{id}"
+		}
+
+		gen := changelog.Generate(commits, issueURL)
+		if gen.Bump == "" {
+			fmt.Fprintln(out, "No changelog entries in the commits since "+sinceName(since))
+			return
+		}
+
+		if changelogWrite {
+			data, err := vfs.ReadFile(fsys, changelog.File)
+			if err != nil {
+				utils.CheckErrFatal(err)
+			}
+
+			// the entries already listed in [Unreleased], whatever their change type
+			listed := map[string]bool{}
+			for _, r := range changelog.Parse(data).Releases {
+				if !r.Unreleased() {
+					continue
+				}
+				for _, s := range r.Sections {
+					for _, e := range s.Entries {
+						listed[e.Text] = true
+					}
+				}
+				break
+			}
+
+			added := 0
+			for _, t := range changelog.Types {
+				for _, e := range gen.Entries[t] {
+					if listed[strings.TrimSpace(e)] {
+						continue
+					}
+					listed[strings.TrimSpace(e)] = true
+					if data, err = changelog.Add(data, t, e); err != nil {
+						utils.CheckErrFatal(err)
+					}
+					added++
+				}
+			}
+
+			if err := vfs.WriteFile(fsys, changelog.File, data, 0644); err != nil {
+				utils.CheckErrFatal(err)
+			}
+			fmt.Fprintf(out, "Added %d entries to [Unreleased] from the commits since %s\n", added, sinceName(since))
+		} else {
+			fmt.Fprint(out, gen.Markdown())
+			out = cmd.ErrOrStderr()
+		}
+
+		suggestion := "Suggested version bump: " + gen.Bump
+		if data, err := vfs.ReadFile(fsys, release.VersionFile); err == nil {
+			if v, err := semver.Parse(string(data)); err == nil {
+				if next, err := v.Bump(gen.Bump, ""); err == nil {
+					suggestion += fmt.Sprintf(", %s to %s", v, next)
+				}
+			}
+		}
+		fmt.Fprintln(out, suggestion)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(changelogCmd)
+	changelogCmd.AddCommand(changelogAddCmd)
+	changelogCmd.AddCommand(changelogLintCmd)
+	changelogCmd.AddCommand(changelogGenerateCmd)
+
+	// persistent flags for changelogCmd and its subcommands
+	changelogCmd.PersistentFlags().StringVarP(&changelogLocation, "location", "l", ".", "location inside the project, go.mod is searched from there upwards")
+
+	// local flags for changelogAddCmd
+	changelogAddCmd.Flags().StringVarP(&changelogType, "type", "t", "added", "change type of the entry: "+strings.ToLower(strings.Join(changelog.Types, ", ")))
+
+	// local flags for changelogLintCmd
+	changelogLintCmd.Flags().StringVar(&changelogFormat, "format", "text", "output format: "+strings.Join(lint.Formats, ", "))
+
+	// local flags for changelogGenerateCmd
+	changelogGenerateCmd.Flags().StringVar(&changelogSince, "since", "", "tag or revision the commits are read after, empty for the whole history (default is the latest tag)")
+	changelogGenerateCmd.Flags().StringVar(&changelogIssueURL, "issue-url", "", "URL of the issues, {id} is the issue number (default is the issueURL key of the config file, or the issues of GitHub modules)")
+	changelogGenerateCmd.Flags().BoolVar(&changelogWrite, "write", false, "adds the entries to the [Unreleased] section of CHANGELOG.md instead of printing them")
+}
+
+// sinceName returns the name of the revision the commits are read after
+func sinceName(since string) string {
+	if since == "" {
+		return "the first commit"
+	}
+	return since
+}
+
+// changelogFS returns the root of the project containing the changelog location
+func changelogFS() vfs.FS {
+	return vfs.BasePath(vfs.OS(), changelogRoot())
+}
+
+// changelogRoot returns the root directory of the project containing the changelog location
+func changelogRoot() string {
+	root, err := gomod.FindRoot(vfs.OS(), changelogLocation)
+	if errors.Is(err, gomod.ErrNoModule) {
+		return changelogLocation
+	} else if err != nil {
+		utils.CheckErrFatal(err)
+	}
+	return root
+}
@@ -22,101 +22,178 @@ THE SOFTWARE.
 package cmd
 
 import (
-	"embed"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
-	"regexp"
-	"runtime"
+	"strings"
 
+	"github.com/dark-shade/go-setup/pkg/ci"
+	"github.com/dark-shade/go-setup/pkg/docker"
+	"github.com/dark-shade/go-setup/pkg/packaging"
+	"github.com/dark-shade/go-setup/pkg/scaffold"
+	"github.com/dark-shade/go-setup/pkg/task"
 	"github.com/dark-shade/go-setup/pkg/utils"
+	"github.com/dark-shade/go-setup/pkg/vfs"
 	"github.com/spf13/cobra"
 )
 
 var (
-	full       bool
-	ops        bool
-	license    string
-	location   string
-	author     string
-	modulePath string
-	profiles   []string
-	config     bool
+	full          bool
+	ops           bool
+	license       string
+	location      string
+	author        string
+	modulePath    string
+	profiles      []string
+	config        bool
+	dryRun        bool
+	archive       string
+	output        string
+	projectType   string
+	ciProvider    string
+	dockerRuntime string
+	cgo           bool
+	runner        string
+	deploy        string
+	releaseTool   string
 )
 
-//go:embed data/*
-var f embed.FS
-
 // initCmd represents the init command
 var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initializes a project",
 	Long:  `Initializes a project by adding recommended directory structure and files.`,
+	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		// check location exists
-		locationExists, err := utils.Exists(location)
-		if err != nil {
-			utils.CheckErrFatal(err)
+		// messages go to stderr when the archive is streamed
+		out := cmd.OutOrStdout()
+		if archive != "" {
+			out = cmd.ErrOrStderr()
 		}
 
-		if !locationExists {
-			utils.CheckErrFatal(errors.New("location to initialize project doesn't exist"))
+		// check location exists, archives are not written to the location
+		if archive == "" {
+			locationExists, err := utils.Exists(vfs.OS(), location)
+			if err != nil {
+				utils.CheckErrFatal(err)
+			}
+
+			if !locationExists {
+				utils.CheckErrFatal(errors.New("location to initialize project doesn't exist"))
+			}
 		}
 
 		// create .go-setup directory structure in user home
-		homeDirPath, err := os.UserHomeDir()
+		profilesDir, err := profilesPath()
 		if err != nil {
 			utils.CheckErrNonFatal(err)
 		}
 
-		if err := os.MkdirAll(filepath.Join(homeDirPath, ".go-setup", "profiles"), os.ModePerm); err != nil {
+		if err := os.MkdirAll(profilesDir, os.ModePerm); err != nil {
 			utils.CheckErrNonFatal(err)
 		} else {
-			fmt.Println("Config and profiles path setup up at " + filepath.Join(homeDirPath, ".go-setup", "profiles"))
+			fmt.Fprintln(out, "Config and profiles path setup up at "+profilesDir)
 		}
 
 		if config {
 			return
 		}
 
-		// location for file definitions and data
-		var profileLoc string
+		gen := scaffold.New(scaffoldOptions(profilesDir))
+
+		var archiveSink *scaffold.ArchiveSink
+		switch {
+		case archive != "":
+			w := io.Writer(os.Stdout)
+			if output != "-" {
+				file, err := os.Create(output)
+				if err != nil {
+					utils.CheckErrFatal(err)
+				}
+				defer file.Close()
+				w = file
+			}
 
-		if len(profiles) > 1 || profiles[0] != "default" {
-			// get home directory
-			homeDirPath, err := os.UserHomeDir()
+			archiveSink, err = scaffold.NewArchiveSink(w, archive)
 			if err != nil {
-				utils.CheckErrNonFatal(err)
+				utils.CheckErrFatal(err)
 			}
+			archiveSink.Prefix = path.Base(scaffold.ModulePath(gen.Options))
+			gen.Sink = archiveSink
+		case dryRun:
+			// writes are kept in memory, the location is only read
+			gen.Sink = scaffold.NewFSSink(vfs.Overlay(vfs.BasePath(vfs.OS(), location)))
+		}
+
+		plan, err := gen.Plan()
+		if err != nil {
+			utils.CheckErrFatal(err)
+		}
 
-			fmt.Println("Copying profiles...")
+		if err := checkPolicy(gen, plan); err != nil {
+			utils.CheckErrFatal(err)
+		}
 
-			for _, profile := range profiles {
-				fmt.Println("Copying profile: " + profile)
+		switch {
+		case full:
+			fmt.Fprintln(out, "Setting up full-scale project structure...")
+		case ops:
+			fmt.Fprintln(out, "Setting up bare-minimum and operations project structure...")
+		default:
+			fmt.Fprintln(out, "Setting up bare-minimum project structure...")
+		}
 
-				profileLoc = filepath.Join(homeDirPath, ".go-setup", "profiles", profile)
+		// we cannot just stop on errors since these are all non-fatal errors
+		res := gen.Apply(plan)
+		for _, err := range res.Errors {
+			utils.CheckErrNonFatal(err)
+		}
 
-				utils.CopyDirectory(profileLoc, location)
+		// the manifest of the archive comes after its go.mod and go.sum are tidied
+		if archiveSink != nil && scaffold.NeedsTidy(gen.Options) {
+			fmt.Fprintln(out, "Downloading dependencies with go mod tidy...")
+			if err := archiveSink.Tidy(); err != nil {
+				utils.CheckErrNonFatal(err)
 			}
 		}
 
-		if full {
-			fmt.Println("Setting up full-scale project structure...")
-			bareSetup()
-			opsSetup()
-			remainderSetup()
-			fmt.Println("Finished full-scale project structure setup")
-		} else {
-			fmt.Println("Setting up bare-minimum project structure...")
-			bareSetup()
-			fmt.Println("Finished bare-minimum project structure setup")
+		// record what was generated, so go-setup upgrade can merge newer templates later
+		manifest := scaffold.NewManifest(plan, res)
+		if archiveSink == nil {
+			if old, err := scaffold.ReadManifest(vfs.BasePath(vfs.OS(), location)); err == nil {
+				manifest.Merge(old)
+			} else if !os.IsNotExist(err) {
+				utils.CheckErrNonFatal(err)
+			}
+		}
+
+		if err := scaffold.WriteManifest(gen.Sink, manifest, plan); err != nil {
+			utils.CheckErrNonFatal(err)
 		}
 
-		if ops {
-			fmt.Println("Setting up operations project structure...")
-			opsSetup()
-			fmt.Println("Finished operations project structure setup")
+		switch {
+		case archiveSink != nil:
+			if err := archiveSink.Close(); err != nil {
+				utils.CheckErrFatal(err)
+			}
+			fmt.Fprintf(out, "Finished project archive, added %d entries\n", len(res.Created))
+		case dryRun:
+			for _, p := range res.Created {
+				fmt.Fprintln(out, "Would create: "+p)
+			}
+			fmt.Fprintf(out, "Finished dry run, would create %d and skip %d entries\n", len(res.Created), len(res.Skipped))
+		default:
+			fmt.Fprintf(out, "Finished project structure setup, created %d and skipped %d entries\n", len(res.Created), len(res.Skipped))
+
+			if scaffold.NeedsTidy(gen.Options) {
+				fmt.Fprintln(out, "Downloading dependencies with go mod tidy...")
+				if err := scaffold.Tidy(location); err != nil {
+					utils.CheckErrNonFatal(err)
+				}
+			}
 		}
 	},
 }
@@ -126,13 +203,24 @@ func init() {
 
 	// local flags for initCmd
 	initCmd.Flags().BoolVarP(&full, "full", "f", false, "initializes all files and directories in the recommend layout")
-	initCmd.Flags().BoolVarP(&ops, "ops", "o", false, "initializes all the operations related files (also initializes bare-minimum setup)")
+	initCmd.Flags().BoolVar(&ops, "ops", false, "initializes all the operations related files (also initializes bare-minimum setup)")
 	initCmd.Flags().StringVarP(&license, "license", "i", "mit", "initializes the license")
 	initCmd.Flags().StringVarP(&location, "location", "l", ".", "location for project structure setup")
 	initCmd.Flags().StringVarP(&author, "author", "a", "", "author name and email, e.g. Jane Doe jane.doe@gmail.com")
 	initCmd.Flags().StringVarP(&modulePath, "moduleP-path", "m", ".", "module path for go mod init")
 	initCmd.Flags().StringSliceVarP(&profiles, "profile", "p", []string{"default"}, "profile to use for project setup")
 	initCmd.Flags().BoolVarP(&config, "config", "c", false, "initializes the ~/.go-setup/profiles path")
+	initCmd.Flags().BoolVar(&dryRun, "dry-run", false, "prints what would be created without writing to the location")
+	initCmd.Flags().StringVar(&archive, "archive", "", "writes the project as an archive instead of to the location, tar.gz or zip")
+	initCmd.Flags().StringVarP(&output, "output", "o", "-", "file the archive is written to, - for stdout")
+	initCmd.Flags().StringVarP(&projectType, "type", "t", "", "project type: "+strings.Join(presetNames(), ", "))
+	initCmd.Flags().StringVar(&ciProvider, "ci", "", "CI provider to generate the pipeline of: "+strings.Join(ci.Providers(), ", "))
+	initCmd.Flags().StringVar(&deploy, "deploy", "", "deployment manifests of services to generate in deployments/: helm, k8s, kustomize")
+	initCmd.Flags().StringVar(&releaseTool, "release", "", "release tool to generate the configuration of, with nfpm packages: "+strings.Join(packaging.Tools, ", "))
+	initCmd.Flags().StringVar(&runner, "runner", task.DefaultRunner, "task runner to generate the file of: "+strings.Join(task.Runners(), ", "))
+	initCmd.Flags().StringVar(&dockerRuntime, "runtime", "", "runtime image of the Dockerfile: "+strings.Join(docker.Runtimes, ", ")+" (default distroless)")
+	initCmd.Flags().BoolVar(&cgo, "cgo", false, "builds the binaries of the Dockerfile with cgo enabled")
+	initCmd.Flags().StringVar(&policyFile, "policy", "", "policy file the project has to comply with (default is the policy key of the config file)")
 
 	// Here you will define your flags and configuration settings.
 
@@ -145,196 +233,73 @@ func init() {
 	// initCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
 
-// bareSetup setups the bare structure
-func bareSetup() {
-	// we cannot just return errors since these are all non-fatal errors
-	// setup directories
-	if err := os.Mkdir(filepath.Join(location, "bin"), os.ModePerm); err != nil {
-		utils.CheckErrNonFatal(err)
-	}
-
-	if err := os.Mkdir(filepath.Join(location, "configs"), os.ModePerm); err != nil {
-		utils.CheckErrNonFatal(err)
-	}
-
-	if err := os.Mkdir(filepath.Join(location, "docs"), os.ModePerm); err != nil {
-		utils.CheckErrNonFatal(err)
-	}
-
-	if err := os.Mkdir(filepath.Join(location, "examples"), os.ModePerm); err != nil {
-		utils.CheckErrNonFatal(err)
-	}
-
-	if err := os.Mkdir(filepath.Join(location, "pkg"), os.ModePerm); err != nil {
-		utils.CheckErrNonFatal(err)
-	}
-
-	if err := os.Mkdir(filepath.Join(location, "scripts"), os.ModePerm); err != nil {
-		utils.CheckErrNonFatal(err)
-	}
-
-	if err := os.MkdirAll(filepath.Join(location, "test", "data"), os.ModePerm); err != nil {
-		utils.CheckErrNonFatal(err)
-	}
-
-	// setup files
-	/// main.go file
-	mainData, err := f.ReadFile(filepath.Join(".", "data", "main.go"))
-	if err != nil {
-		utils.CheckErrNonFatal(err)
-	} else {
-		if err := utils.CreateFile(filepath.Join(location, "main.go"), mainData, os.ModePerm); err != nil {
-			utils.CheckErrNonFatal(err)
-		}
-	}
-
-	/// .gitignore file
-	gitignoreData, err := f.ReadFile(filepath.Join(".", "data", ".gitignore"))
-	if err != nil {
-		utils.CheckErrNonFatal(err)
-	} else {
-		if err := utils.CreateFile(filepath.Join(location, ".gitignore"), gitignoreData, os.ModePerm); err != nil {
-			utils.CheckErrNonFatal(err)
-		}
-	}
-
-	/// Makefile file
-	makefileData, err := f.ReadFile(filepath.Join(".", "data", "Makefile"))
+// profilesPath returns the path of the profiles directory in the user home
+func profilesPath() (string, error) {
+	homeDirPath, err := os.UserHomeDir()
 	if err != nil {
-		utils.CheckErrNonFatal(err)
-	} else {
-		if err := utils.CreateFile(filepath.Join(location, "Makefile"), makefileData, os.ModePerm); err != nil {
-			utils.CheckErrNonFatal(err)
-		}
-	}
-
-	/// README.md file
-	readmeData, err := f.ReadFile(filepath.Join(".", "data", "README.md"))
-	if err != nil {
-		utils.CheckErrNonFatal(err)
-	} else {
-		if err := utils.CreateFile(filepath.Join(location, "README.md"), readmeData, os.ModePerm); err != nil {
-			utils.CheckErrNonFatal(err)
-		}
-	}
-
-	/// LICENSE file
-	if license == "mit" {
-		licenseData, err := f.ReadFile(filepath.Join(".", "data", "licenses", "mit"))
-		if err != nil {
-			utils.CheckErrNonFatal(err)
-		} else {
-			if err := utils.CreateFile(filepath.Join(location, "LICENSE"), licenseData, os.ModePerm); err != nil {
-				utils.CheckErrNonFatal(err)
-			}
-		}
-	} else if license == "apache" {
-		licenseData, err := f.ReadFile(filepath.Join(".", "data", "licenses", "apache"))
-		if err != nil {
-			utils.CheckErrNonFatal(err)
-		} else {
-			if err := utils.CreateFile(filepath.Join(location, "LICENSE"), licenseData, os.ModePerm); err != nil {
-				utils.CheckErrNonFatal(err)
-			}
-		}
-	} else {
-		fmt.Println("Error: Invalid license: " + license + ". Valid values are mit or apache")
-	}
-
-	/// go.mod file
-	r, _ := regexp.Compile(`\d.\d+`)
-
-	goModData := "module github.com/dark-shade/temp\ngo " + r.FindString(runtime.Version())
-	if err := utils.CreateFile(filepath.Join(location, "go.mod"), []byte(goModData), os.ModePerm); err != nil {
-		utils.CheckErrNonFatal(err)
+		return "", err
 	}
 
-	/// CHANGELOG.md file
-	changelogData, err := f.ReadFile(filepath.Join(".", "data", "CHANGELOG.md"))
-	if err != nil {
-		utils.CheckErrNonFatal(err)
-	} else {
-		if err := utils.CreateFile(filepath.Join(location, "CHANGELOG.md"), changelogData, os.ModePerm); err != nil {
-			utils.CheckErrNonFatal(err)
-		}
-	}
+	return filepath.Join(homeDirPath, ".go-setup", "profiles"), nil
 }
 
-// opsSetup setups the ops structure
-func opsSetup() {
-	// we cannot just return errors since these are all non-fatal errors
-
-	// create deployment related files
-	/// Dockerfile file
-	dockerfileData, err := f.ReadFile(filepath.Join(".", "data", "Dockerfile"))
-	if err != nil {
-		utils.CheckErrNonFatal(err)
-	} else {
-		if err := utils.CreateFile(filepath.Join(location, "Dockerfile"), dockerfileData, os.ModePerm); err != nil {
-			utils.CheckErrNonFatal(err)
-		}
-	}
-
-	/// Jenkinsfile file
-	jenkinsfileData, err := f.ReadFile(filepath.Join(".", "data", "Jenkinsfile"))
-	if err != nil {
-		utils.CheckErrNonFatal(err)
-	} else {
-		if err := utils.CreateFile(filepath.Join(location, "Jenkinsfile"), jenkinsfileData, os.ModePerm); err != nil {
-			utils.CheckErrNonFatal(err)
-		}
+// presetNames returns the names of the project types
+func presetNames() []string {
+	var names []string
+	for _, p := range scaffold.Presets() {
+		names = append(names, p.Name)
 	}
+	return names
 }
 
-// remainderSetup setups up the full structure excluding the bareSetup and opsSetup
-func remainderSetup() {
-	// we cannot just return errors since these are all non-fatal errors
-	// setup directories
-	if err := os.Mkdir(filepath.Join(location, "api"), os.ModePerm); err != nil {
-		utils.CheckErrNonFatal(err)
-	}
-
-	if err := os.Mkdir(filepath.Join(location, "assets"), os.ModePerm); err != nil {
-		utils.CheckErrNonFatal(err)
-	}
-
-	if err := os.Mkdir(filepath.Join(location, "build"), os.ModePerm); err != nil {
-		utils.CheckErrNonFatal(err)
-	}
-
-	if err := os.Mkdir(filepath.Join(location, "cmd"), os.ModePerm); err != nil {
-		utils.CheckErrNonFatal(err)
-	}
-
-	if err := os.Mkdir(filepath.Join(location, "deployments"), os.ModePerm); err != nil {
-		utils.CheckErrNonFatal(err)
-	}
-
-	if err := os.Mkdir(filepath.Join(location, "githooks"), os.ModePerm); err != nil {
-		utils.CheckErrNonFatal(err)
+// scaffoldOptions returns the scaffold options set by the init flags
+func scaffoldOptions(profilesDir string) scaffold.Options {
+	return scaffold.Options{
+		Location:    location,
+		License:     license,
+		Author:      author,
+		ModulePath:  modulePath,
+		Profiles:    profiles,
+		ProfilesDir: profilesDir,
+		Full:        full,
+		Ops:         ops,
+		Type:        projectType,
+		CI:          ciProvider,
+		Runtime:     dockerRuntime,
+		CGO:         cgo,
+		Runner:      runner,
+		Deploy:      deploy,
+		Release:     releaseTool,
 	}
+}
 
-	if err := os.Mkdir(filepath.Join(location, "init"), os.ModePerm); err != nil {
-		utils.CheckErrNonFatal(err)
+// checkPolicy applies the plan to a copy of the location in memory and checks it against the policy, if any
+func checkPolicy(gen *scaffold.Generator, plan *scaffold.Plan) error {
+	pol, err := loadPolicy()
+	if err != nil || pol == nil {
+		return err
 	}
 
-	if err := os.Mkdir(filepath.Join(location, "internal"), os.ModePerm); err != nil {
-		utils.CheckErrNonFatal(err)
+	fsys := vfs.Memory()
+	if archive == "" {
+		fsys = vfs.Overlay(vfs.BasePath(vfs.OS(), location))
 	}
 
-	if err := os.Mkdir(filepath.Join(location, "third_party"), os.ModePerm); err != nil {
-		utils.CheckErrNonFatal(err)
-	}
+	check := *gen
+	check.Sink = scaffold.NewFSSink(fsys)
+	check.Apply(plan)
 
-	if err := os.Mkdir(filepath.Join(location, "tools"), os.ModePerm); err != nil {
-		utils.CheckErrNonFatal(err)
+	violations, err := pol.Check(fsys)
+	if err != nil {
+		return err
 	}
-
-	if err := os.Mkdir(filepath.Join(location, "web"), os.ModePerm); err != nil {
-		utils.CheckErrNonFatal(err)
+	if len(violations) == 0 {
+		return nil
 	}
 
-	if err := os.Mkdir(filepath.Join(location, "website"), os.ModePerm); err != nil {
-		utils.CheckErrNonFatal(err)
+	msg := "the project would violate the policy:"
+	for _, v := range violations {
+		msg += "\n  " + v.String()
 	}
+	return errors.New(msg)
 }
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInitArchiveOutput(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	output := filepath.Join(t.TempDir(), "project.tgz")
+
+	rootCmd.SetArgs([]string{"init", "--archive", "tar.gz", "-o", output, "-m", "example.com/jane/project"})
+	rootCmd.SetOut(io.Discard)
+	rootCmd.SetErr(io.Discard)
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := map[string]bool{}
+	r := tar.NewReader(gz)
+	for {
+		h, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names[h.Name] = true
+	}
+
+	for _, name := range []string{"project/go.mod", "project/.go-setup/manifest.yaml"} {
+		if !names[name] {
+			t.Errorf("%s is not in the archive", name)
+		}
+	}
+	// -o is the output, not --ops
+	if names["project/Jenkinsfile"] {
+		t.Error("the archive has the operations files")
+	}
+}
+
+func TestInitArgs(t *testing.T) {
+	rootCmd.SetArgs([]string{"init", "--archive", "tar.gz", "project.tgz"})
+	rootCmd.SetOut(io.Discard)
+	rootCmd.SetErr(io.Discard)
+	if err := rootCmd.Execute(); err == nil {
+		t.Fatal("got no error for the argument project.tgz")
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright © 2021 Sankul Rawat sankul.rawat.28@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dark-shade/go-setup/pkg/gomod"
+	"github.com/dark-shade/go-setup/pkg/lint"
+	"github.com/dark-shade/go-setup/pkg/utils"
+	"github.com/dark-shade/go-setup/pkg/vfs"
+	"github.com/spf13/cobra"
+)
+
+var (
+	lintLocation string
+	lintRules    string
+	lintFormat   string
+)
+
+// lintCmd represents the lint command
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Checks whether a project follows the standard layout",
+	Long: `Checks whether the project containing the location follows golang-standards/project-layout and reports rule violations.
+
+The severity of each rule can be changed, or the rule disabled, in a rules file, by default .go-setup/lint.yaml:
+
+  rules:
+    license: error
+    pkg-private: off
+  ignore:
+    - examples/*
+
+Rules:
+` + lintRulesHelp(),
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		root, err := gomod.FindRoot(vfs.OS(), lintLocation)
+		if errors.Is(err, gomod.ErrNoModule) {
+			root = lintLocation
+		} else if err != nil {
+			utils.CheckErrFatal(err)
+		}
+
+		fsys := vfs.ReadOnly(vfs.BasePath(vfs.OS(), root))
+
+		var cfg *lint.Config
+		switch {
+		case lintRules != "":
+			cfg, err = lint.LoadConfig(vfs.OS(), lintRules)
+		default:
+			cfg, err = lint.LoadConfig(fsys, lint.DefaultConfigPath)
+			if os.IsNotExist(err) {
+				cfg, err = nil, nil
+			}
+		}
+		if err != nil {
+			utils.CheckErrFatal(err)
+		}
+
+		findings, err := lint.Run(fsys, cfg)
+		if err != nil {
+			utils.CheckErrFatal(err)
+		}
+
+		if err := lint.Write(cmd.OutOrStdout(), lintFormat, findings); err != nil {
+			utils.CheckErrFatal(err)
+		}
+
+		if lint.Count(findings, lint.Error) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+
+	// local flags for lintCmd
+	lintCmd.Flags().StringVarP(&lintLocation, "location", "l", ".", "location inside the project, go.mod is searched from there upwards")
+	lintCmd.Flags().StringVar(&lintRules, "rules", "", "rules file (default is .go-setup/lint.yaml of the project, if any)")
+	lintCmd.Flags().StringVar(&lintFormat, "format", "text", "output format: "+strings.Join(lint.Formats, ", "))
+}
+
+// lintRulesHelp returns the help lines describing the rules
+func lintRulesHelp() string {
+	var b strings.Builder
+	for _, r := range lint.Rules() {
+		fmt.Fprintf(&b, "  %-16s %-8s %s\n", r.ID, r.Severity, r.Description)
+	}
+	return b.String()
+}
@@ -0,0 +1,80 @@
+/*
+Copyright © 2021 Sankul Rawat sankul.rawat.28@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/dark-shade/go-setup/pkg/gomod"
+	"github.com/dark-shade/go-setup/pkg/task"
+	"github.com/dark-shade/go-setup/pkg/utils"
+	"github.com/dark-shade/go-setup/pkg/vfs"
+	"github.com/spf13/cobra"
+)
+
+var makefileLocation string
+
+// makefileCmd represents the makefile command
+var makefileCmd = &cobra.Command{
+	Use:   "makefile",
+	Short: "Manages the generated Makefile of a project",
+}
+
+// makefileSyncCmd represents the makefile sync command
+var makefileSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Regenerates the targets of the Makefile",
+	Long: `Regenerates the targets of the Makefile of the project containing the location from its main packages,
+e.g. after a binary was added to cmd/. Only the section between the go-setup:begin and go-setup:end
+lines is replaced, the other targets are kept. The Makefile is created when the project has none.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		out := cmd.OutOrStdout()
+
+		root, err := gomod.FindRoot(vfs.OS(), makefileLocation)
+		if err != nil {
+			utils.CheckErrFatal(err)
+		}
+
+		res, err := task.Sync(vfs.BasePath(vfs.OS(), root))
+		if err != nil {
+			utils.CheckErrFatal(err)
+		}
+
+		for _, b := range res.Config.Binaries {
+			fmt.Fprintf(out, "Binary: %s (%s)\n", b.Name, b.Package)
+		}
+		if !res.Changed {
+			fmt.Fprintln(out, "Makefile is up to date")
+			return
+		}
+		fmt.Fprintln(out, "Updated: Makefile")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(makefileCmd)
+	makefileCmd.AddCommand(makefileSyncCmd)
+
+	// local flags for makefileSyncCmd
+	makefileSyncCmd.Flags().StringVarP(&makefileLocation, "location", "l", ".", "location inside the project, go.mod is searched from there upwards")
+}
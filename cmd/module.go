@@ -0,0 +1,84 @@
+/*
+Copyright © 2021 Sankul Rawat sankul.rawat.28@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/dark-shade/go-setup/pkg/gomod"
+	"github.com/dark-shade/go-setup/pkg/rename"
+	"github.com/dark-shade/go-setup/pkg/utils"
+	"github.com/dark-shade/go-setup/pkg/vfs"
+	"github.com/spf13/cobra"
+)
+
+var moduleLocation string
+
+// moduleCmd represents the module command
+var moduleCmd = &cobra.Command{
+	Use:   "module",
+	Short: "Changes the go module of a project",
+}
+
+// moduleRenameCmd represents the module rename command
+var moduleRenameCmd = &cobra.Command{
+	Use:   "rename <new-path>",
+	Short: "Renames the module of a project",
+	Long: `Renames the module of the project containing the location to new-path. It updates go.mod, rewrites
+the imports and comments of every go file, test files and files behind build tags included, updates the
+references of the build and release files, of the init system configurations of init/ and of the files
+recorded in the .go-setup manifest, and reports the string literals still containing the old module path,
+which are left untouched.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		out := cmd.OutOrStdout()
+
+		root, err := gomod.FindRoot(vfs.OS(), moduleLocation)
+		if err != nil {
+			utils.CheckErrFatal(err)
+		}
+
+		res, err := rename.Module(vfs.BasePath(vfs.OS(), root), args[0])
+		if err != nil {
+			utils.CheckErrFatal(err)
+		}
+
+		for _, p := range res.Rewritten {
+			fmt.Fprintln(out, "Rewrote: "+p)
+		}
+		for _, p := range res.Updated {
+			fmt.Fprintln(out, "Updated: "+p)
+		}
+		for _, l := range res.Literals {
+			fmt.Fprintf(out, "Left untouched: %s:%d: %s\n", l.Path, l.Line, l.Value)
+		}
+		fmt.Fprintf(out, "Renamed module %s to %s\n", res.Old, res.New)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(moduleCmd)
+	moduleCmd.AddCommand(moduleRenameCmd)
+
+	// local flags for moduleRenameCmd
+	moduleRenameCmd.Flags().StringVarP(&moduleLocation, "location", "l", ".", "location inside the project, go.mod is searched from there upwards")
+}
@@ -0,0 +1,75 @@
+/*
+Copyright © 2021 Sankul Rawat sankul.rawat.28@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/dark-shade/go-setup/pkg/gomod"
+	"github.com/dark-shade/go-setup/pkg/refactor"
+	"github.com/dark-shade/go-setup/pkg/utils"
+	"github.com/dark-shade/go-setup/pkg/vfs"
+	"github.com/spf13/cobra"
+)
+
+var mvLocation string
+
+// mvCmd represents the mv command
+var mvCmd = &cobra.Command{
+	Use:   "mv <old-pkg-dir> <new-pkg-dir>",
+	Short: "Moves or renames a package of a project",
+	Long: `Moves the package in old-pkg-dir, and the packages below it, to new-pkg-dir, both relative to the root
+of the project containing the location, e.g. go-setup mv internal/foo pkg/foo.
+
+The package clause is renamed when the directory name changes, and every importer of the module is rewritten.
+Moves breaking the visibility rules of internal packages, or with go files that cannot be parsed, are refused
+before anything is changed, and a move failing halfway is undone.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		root, err := gomod.FindRoot(vfs.OS(), mvLocation)
+		if err != nil {
+			utils.CheckErrFatal(err)
+		}
+
+		res, err := refactor.Move(vfs.BasePath(vfs.OS(), root), args[0], args[1])
+		if err != nil {
+			utils.CheckErrFatal(err)
+		}
+
+		for _, p := range res.Rewritten {
+			fmt.Fprintln(cmd.OutOrStdout(), "Rewrote: "+p)
+		}
+
+		msg := fmt.Sprintf("Moved %s to %s", res.From, res.To)
+		if res.NewName != res.OldName {
+			msg += fmt.Sprintf(", renamed package %s to %s", res.OldName, res.NewName)
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), msg)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mvCmd)
+
+	// local flags for mvCmd
+	mvCmd.Flags().StringVarP(&mvLocation, "location", "l", ".", "location inside the project, go.mod is searched from there upwards")
+}
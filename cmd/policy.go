@@ -0,0 +1,127 @@
+/*
+Copyright © 2021 Sankul Rawat sankul.rawat.28@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/dark-shade/go-setup/pkg/gomod"
+	"github.com/dark-shade/go-setup/pkg/policy"
+	"github.com/dark-shade/go-setup/pkg/utils"
+	"github.com/dark-shade/go-setup/pkg/vfs"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	policyFile     string
+	policyLocation string
+)
+
+// policyCmd represents the policy command
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Checks projects against the policy of the organization",
+	Long: `Checks projects against a policy file, given with --policy or with the policy key of the config file.
+
+A policy declares the paths every project has or must not have, the allowed licenses, the targets of the
+Makefile and the settings of go.mod:
+
+  required:
+    - CODEOWNERS
+    - SECURITY.md
+  forbidden:
+    - src
+  licenses: [mit, apache]
+  makefile:
+    targets: [test, lint]
+  gomod:
+    module: github.com/acme/*
+    go: "1.17"
+
+init refuses to generate projects violating the policy.`,
+}
+
+// policyCheckCmd represents the policy check command
+var policyCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Checks an existing project against the policy",
+	Long:  `Checks the project containing the location against the policy, and exits with status 1 when it violates it.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		pol, err := loadPolicy()
+		if err != nil {
+			utils.CheckErrFatal(err)
+		}
+		if pol == nil {
+			utils.CheckErrFatal(errors.New("no policy, set it with --policy or the policy key of the config file"))
+		}
+
+		root, err := gomod.FindRoot(vfs.OS(), policyLocation)
+		if errors.Is(err, gomod.ErrNoModule) {
+			root = policyLocation
+		} else if err != nil {
+			utils.CheckErrFatal(err)
+		}
+
+		violations, err := pol.Check(vfs.ReadOnly(vfs.BasePath(vfs.OS(), root)))
+		if err != nil {
+			utils.CheckErrFatal(err)
+		}
+
+		for _, v := range violations {
+			fmt.Fprintln(cmd.OutOrStdout(), v)
+		}
+
+		if len(violations) > 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s violates the policy, %d violations\n", root, len(violations))
+			os.Exit(1)
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), root+" complies with the policy")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(policyCmd)
+	policyCmd.AddCommand(policyCheckCmd)
+
+	// persistent flags for policyCmd, init defines its own
+	policyCmd.PersistentFlags().StringVar(&policyFile, "policy", "", "policy file (default is the policy key of the config file)")
+
+	// local flags for policyCheckCmd
+	policyCheckCmd.Flags().StringVarP(&policyLocation, "location", "l", ".", "location inside the project, go.mod is searched from there upwards")
+}
+
+// loadPolicy returns the policy of the --policy flag or of the config file, nil when there is none
+func loadPolicy() (*policy.Policy, error) {
+	name := policyFile
+	if name == "" {
+		name = viper.GetString("policy")
+	}
+	if name == "" {
+		return nil, nil
+	}
+
+	return policy.Load(vfs.OS(), name)
+}
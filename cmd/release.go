@@ -0,0 +1,111 @@
+/*
+Copyright © 2021 Sankul Rawat sankul.rawat.28@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dark-shade/go-setup/pkg/gomod"
+	"github.com/dark-shade/go-setup/pkg/release"
+	"github.com/dark-shade/go-setup/pkg/semver"
+	"github.com/dark-shade/go-setup/pkg/utils"
+	"github.com/dark-shade/go-setup/pkg/vfs"
+	"github.com/spf13/cobra"
+)
+
+var (
+	releaseLocation string
+	releaseTag      bool
+	releasePreID    string
+	releaseDryRun   bool
+)
+
+// releaseCmd represents the release command
+var releaseCmd = &cobra.Command{
+	Use:   "release <" + strings.Join(semver.Parts, "|") + ">",
+	Short: "Bumps the version of a project and releases its changelog",
+	Long: `Bumps the semantic version of the VERSION file of the project containing the location, and moves the
+changes of the [Unreleased] section of its CHANGELOG.md to a section of the new version dated today. The
+compare links at the bottom of the changelog are updated for the tag of the version.
+
+With --tag, VERSION and CHANGELOG.md are committed and the commit is tagged with an annotated tag, e.g. v1.2.3.
+Nothing is written when git is missing, the tracked files have uncommitted changes or the tag already exists.`,
+	Args:      cobra.ExactValidArgs(1),
+	ValidArgs: semver.Parts,
+	Run: func(cmd *cobra.Command, args []string) {
+		out := cmd.OutOrStdout()
+
+		root, err := gomod.FindRoot(vfs.OS(), releaseLocation)
+		if err != nil {
+			utils.CheckErrFatal(err)
+		}
+
+		// writes are kept in memory for a dry run
+		fsys := vfs.BasePath(vfs.OS(), root)
+		if releaseDryRun {
+			fsys = vfs.Overlay(fsys)
+		}
+
+		opts := release.Options{Part: args[0], PreID: releasePreID}
+
+		// the version is bumped in memory first, so nothing is written when it cannot be tagged
+		if releaseTag {
+			planned, err := release.Bump(vfs.Overlay(fsys), opts)
+			if err != nil {
+				utils.CheckErrFatal(err)
+			}
+			if err := release.CheckTag(root, planned.Version); err != nil {
+				utils.CheckErrFatal(err)
+			}
+		}
+
+		res, err := release.Bump(fsys, opts)
+		if err != nil {
+			utils.CheckErrFatal(err)
+		}
+
+		if releaseDryRun {
+			fmt.Fprintf(out, "Would release %s, previous version %s\n", res.Version, res.Previous)
+			return
+		}
+		fmt.Fprintf(out, "Released %s, previous version %s\n", res.Version, res.Previous)
+
+		if !releaseTag {
+			return
+		}
+		if err := release.Tag(root, res); err != nil {
+			utils.CheckErrFatal(err)
+		}
+		fmt.Fprintln(out, "Tagged: "+res.Version.Tag())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(releaseCmd)
+
+	// local flags for releaseCmd
+	releaseCmd.Flags().StringVarP(&releaseLocation, "location", "l", ".", "location inside the project, go.mod is searched from there upwards")
+	releaseCmd.Flags().BoolVar(&releaseTag, "tag", false, "commits VERSION and CHANGELOG.md and creates an annotated git tag of the version")
+	releaseCmd.Flags().StringVar(&releasePreID, "preid", "", "identifier of new prerelease versions (default "+semver.DefaultPreID+")")
+	releaseCmd.Flags().BoolVar(&releaseDryRun, "dry-run", false, "prints the new version without writing the files")
+}
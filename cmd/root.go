@@ -36,7 +36,7 @@ var rootCmd = &cobra.Command{
 	Use:   "go-setup",
 	Short: "A CLI app for setting up golang projects",
 	Long: `A CLI app that provides the ability to setup and modify structure of multiple types of golang projects.
-It losely follows https://github.com/golang-standards/project-layout`,
+It loosely follows https://github.com/golang-standards/project-layout`,
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	// Run: func(cmd *cobra.Command, args []string) { },
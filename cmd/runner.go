@@ -0,0 +1,79 @@
+/*
+Copyright © 2021 Sankul Rawat sankul.rawat.28@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dark-shade/go-setup/pkg/gomod"
+	"github.com/dark-shade/go-setup/pkg/task"
+	"github.com/dark-shade/go-setup/pkg/utils"
+	"github.com/dark-shade/go-setup/pkg/vfs"
+	"github.com/spf13/cobra"
+)
+
+var runnerLocation string
+
+// runnerCmd represents the runner command
+var runnerCmd = &cobra.Command{
+	Use:   "runner",
+	Short: "Manages the task runner file of a project",
+}
+
+// runnerConvertCmd represents the runner convert command
+var runnerConvertCmd = &cobra.Command{
+	Use:   "convert <" + strings.Join(task.Runners(), "|") + ">",
+	Short: "Generates the task runner file for another runner",
+	Long: `Generates the file of the runner, Makefile, Taskfile.yml or justfile, for the project containing the
+location. The tasks are the ones init generates, derived from the main packages of the project. The file
+of the previous runner is left in place, and its tasks that were not generated by go-setup are listed,
+as they have to be ported by hand.`,
+	Args:      cobra.ExactValidArgs(1),
+	ValidArgs: task.Runners(),
+	Run: func(cmd *cobra.Command, args []string) {
+		out := cmd.OutOrStdout()
+
+		root, err := gomod.FindRoot(vfs.OS(), runnerLocation)
+		if err != nil {
+			utils.CheckErrFatal(err)
+		}
+
+		res, err := task.Convert(vfs.BasePath(vfs.OS(), root), args[0])
+		if err != nil {
+			utils.CheckErrFatal(err)
+		}
+
+		for _, s := range res.Skipped {
+			fmt.Fprintf(out, "Not converted: %s of %s\n", s.Task, s.File)
+		}
+		fmt.Fprintln(out, "Created: "+res.Runner.File)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(runnerCmd)
+	runnerCmd.AddCommand(runnerConvertCmd)
+
+	// local flags for runnerConvertCmd
+	runnerConvertCmd.Flags().StringVarP(&runnerLocation, "location", "l", ".", "location inside the project, go.mod is searched from there upwards")
+}
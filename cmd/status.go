@@ -0,0 +1,129 @@
+/*
+Copyright © 2021 Sankul Rawat sankul.rawat.28@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dark-shade/go-setup/pkg/gomod"
+	"github.com/dark-shade/go-setup/pkg/scaffold"
+	"github.com/dark-shade/go-setup/pkg/utils"
+	"github.com/dark-shade/go-setup/pkg/vfs"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statusLocation  string
+	statusExitCode  bool
+	statusUntracked bool
+)
+
+// statusCmd represents the status command
+var statusCmd = &cobra.Command{
+	Use:     "status",
+	Aliases: []string{"diff"},
+	Short:   "Reports how a project diverges from what init generates",
+	Long: `Compares the project containing the location to what init would generate today with the same options.
+The options are read from .go-setup/manifest.yaml, or default to those of a plain init with the module path of go.mod.
+
+Only the files recorded in the manifest are compared, unless --untracked is given.
+
+Reported paths:
+  missing    generated directories and files missing from the project
+  modified   generated files modified in the project, with a unified diff from the generated content
+  outdated   unmodified files whose template or profile has newer content, with a unified diff of the changes
+  extra      files of the project which are not generated, with --untracked`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		root, err := gomod.FindRoot(vfs.OS(), statusLocation)
+		if err != nil {
+			utils.CheckErrFatal(err)
+		}
+
+		fsys := vfs.ReadOnly(vfs.BasePath(vfs.OS(), root))
+
+		profilesDir, err := profilesPath()
+		if err != nil {
+			utils.CheckErrNonFatal(err)
+		}
+
+		manifest, err := scaffold.ReadManifest(fsys)
+		if err != nil && !os.IsNotExist(err) {
+			utils.CheckErrFatal(err)
+		}
+
+		var opts scaffold.Options
+		if manifest != nil {
+			opts = manifest.Options
+		} else {
+			mod, err := gomod.Read(fsys, ".")
+			if err != nil {
+				utils.CheckErrFatal(err)
+			}
+			opts = scaffold.Options{License: "mit", ModulePath: mod.Module, Profiles: []string{"default"}}
+		}
+		opts.Location = root
+		opts.ProfilesDir = profilesDir
+
+		drifts, err := scaffold.Status(fsys, scaffold.New(opts), manifest, statusUntracked)
+		if err != nil {
+			utils.CheckErrFatal(err)
+		}
+
+		out := cmd.OutOrStdout()
+		for _, d := range drifts {
+			line := fmt.Sprintf("%-9s %s", d.Kind, d.Path)
+			if d.Kind == scaffold.DriftMissing && d.Entry == scaffold.DirEntry {
+				line += "/"
+			}
+			if d.Source != "" {
+				line += " (" + d.Source + ")"
+			}
+			fmt.Fprintln(out, line)
+		}
+
+		for _, d := range drifts {
+			if d.Diff != "" {
+				fmt.Fprint(out, "\n"+strings.TrimSuffix(d.Diff, "\n")+"\n")
+			}
+		}
+
+		if len(drifts) == 0 {
+			fmt.Fprintln(out, "Project matches what init generates")
+		}
+
+		if statusExitCode && len(drifts) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+
+	// local flags for statusCmd
+	statusCmd.Flags().StringVarP(&statusLocation, "location", "l", ".", "location inside the project, go.mod is searched from there upwards")
+	statusCmd.Flags().BoolVar(&statusExitCode, "exit-code", false, "exits with status 1 when the project diverges, for CI")
+	statusCmd.Flags().BoolVar(&statusUntracked, "untracked", false, "also compares the files not recorded in the manifest and reports the files which are not generated")
+}
@@ -0,0 +1,121 @@
+/*
+Copyright © 2021 Sankul Rawat sankul.rawat.28@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dark-shade/go-setup/pkg/gomod"
+	"github.com/dark-shade/go-setup/pkg/scaffold"
+	"github.com/dark-shade/go-setup/pkg/utils"
+	"github.com/dark-shade/go-setup/pkg/vfs"
+	"github.com/spf13/cobra"
+)
+
+var (
+	upgradeLocation string
+	upgradeDryRun   bool
+	upgradeReject   bool
+)
+
+// upgradeCmd represents the upgrade command
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Re-applies the current templates to a generated project",
+	Long: `Re-applies the current templates to the project containing the location, using the options it was generated with.
+
+The content each file was generated with is kept in .go-setup/base. It is the base of a three-way merge
+between the current file and the new template: files not modified since are replaced, modified files get
+the template changes merged in, and conflicting changes are marked with conflict markers, or written to
+a .rej file with --rej.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		root, err := gomod.FindRoot(vfs.OS(), upgradeLocation)
+		if err != nil {
+			utils.CheckErrFatal(err)
+		}
+
+		fsys := vfs.BasePath(vfs.OS(), root)
+		if upgradeDryRun {
+			// writes are kept in memory, the project is only read
+			fsys = vfs.Overlay(fsys)
+		}
+
+		manifest, err := scaffold.ReadManifest(fsys)
+		if os.IsNotExist(err) {
+			utils.CheckErrFatal(fmt.Errorf("%s was not generated by go-setup init, no %s/manifest.yaml", root, scaffold.ManifestDir))
+		}
+		if err != nil {
+			utils.CheckErrFatal(err)
+		}
+
+		profilesDir, err := profilesPath()
+		if err != nil {
+			utils.CheckErrNonFatal(err)
+		}
+
+		opts := manifest.Options
+		opts.Location = root
+		opts.ProfilesDir = profilesDir
+
+		changes, err := scaffold.Upgrade(fsys, scaffold.New(opts), manifest, scaffold.UpgradeOptions{Reject: upgradeReject})
+		if err != nil {
+			utils.CheckErrFatal(err)
+		}
+
+		counts := map[scaffold.UpgradeStatus]int{}
+		for _, c := range changes {
+			counts[c.Status]++
+			if c.Status == scaffold.UpgradeUnchanged {
+				continue
+			}
+
+			line := fmt.Sprintf("%-9s %s", c.Status, c.Path)
+			if c.Detail != "" {
+				line += " (" + c.Detail + ")"
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), line)
+		}
+
+		verb := "Finished upgrade"
+		if upgradeDryRun {
+			verb = "Finished dry run"
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s: %d added, %d updated, %d merged, %d conflicts, %d skipped, %d removed\n", verb,
+			counts[scaffold.UpgradeAdded], counts[scaffold.UpgradeUpdated], counts[scaffold.UpgradeMerged],
+			counts[scaffold.UpgradeConflict], counts[scaffold.UpgradeSkipped], counts[scaffold.UpgradeRemoved])
+
+		if counts[scaffold.UpgradeConflict] > 0 && !upgradeDryRun {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(upgradeCmd)
+
+	// local flags for upgradeCmd
+	upgradeCmd.Flags().StringVarP(&upgradeLocation, "location", "l", ".", "location inside the project, go.mod is searched from there upwards")
+	upgradeCmd.Flags().BoolVar(&upgradeDryRun, "dry-run", false, "prints what would change without writing to the project")
+	upgradeCmd.Flags().BoolVar(&upgradeReject, "rej", false, "keeps conflicting files as they are and writes the template changes to <file>.rej")
+}
@@ -0,0 +1,45 @@
+// Package version reports the version of go-setup.
+package version
+
+import "runtime/debug"
+
+// Version is set at build time from the VERSION file by the Makefile, e.g.
+// -ldflags "-X github.com/dark-shade/go-setup/internal/version.Version=v1.0.0"
+var Version = ""
+
+// Get returns the version set at build time. Without it, it is the version of
+// the module for binaries built by go install, the vcs revision for binaries
+// built in a checkout, or dev.
+func Get() string {
+	if Version != "" {
+		return Version
+	}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "dev"
+	}
+	if info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+
+	var revision, modified string
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.modified":
+			modified = s.Value
+		}
+	}
+	if revision == "" {
+		return "dev"
+	}
+	if len(revision) > 12 {
+		revision = revision[:12]
+	}
+	if modified == "true" {
+		revision += "-dirty"
+	}
+	return "dev+" + revision
+}
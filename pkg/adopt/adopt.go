@@ -0,0 +1,258 @@
+// Package adopt migrates an existing project to the standard layout: main
+// packages are moved to cmd/, private packages to internal/ and shell scripts
+// to scripts/, the imports and build files follow the moves.
+package adopt
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dark-shade/go-setup/pkg/imports"
+	"github.com/dark-shade/go-setup/pkg/lint"
+	"github.com/dark-shade/go-setup/pkg/project"
+	"github.com/dark-shade/go-setup/pkg/vfs"
+)
+
+// standardDirs are the top level directories of the standard layout, they are never moved.
+var standardDirs = map[string]bool{
+	"api": true, "assets": true, "build": true, "cmd": true, "configs": true, "deployments": true,
+	"docs": true, "examples": true, "githooks": true, "init": true, "internal": true, "pkg": true,
+	"scripts": true, "test": true, "third_party": true, "tools": true, "web": true, "website": true,
+}
+
+// buildFiles are the files whose references to moved paths are updated.
+var buildFiles = []string{"Makefile", "Dockerfile"}
+
+// Move is a file or directory moved by the adoption.
+type Move struct {
+	// From and To are slash separated paths relative to the project root.
+	From string
+	To   string
+	// Dir reports whether a whole directory is moved.
+	Dir    bool
+	Reason string
+}
+
+// Plan is the moves adopting a project.
+type Plan struct {
+	Module string
+	// Binary is the name of the binary of a main package in the root, moved to cmd/<binary>.
+	Binary string
+	Moves  []Move
+}
+
+// Result is the outcome of applying a plan.
+type Result struct {
+	// Rewritten are the go files whose imports were rewritten.
+	Rewritten []string
+	// Updated are the build files whose paths were updated.
+	Updated []string
+
+	journal *vfs.Journal
+}
+
+// Analyze inspects the packages of the project at the root of fsys and
+// returns the moves adopting it. The top level directories in keep are left
+// where they are.
+func Analyze(fsys vfs.FS, keep []string) (*Plan, error) {
+	p, err := lint.Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	if p.Module == "" {
+		return nil, errors.New("go.mod not found in the project root")
+	}
+
+	plan := &Plan{Module: p.Module, Binary: project.Name(p.Module)}
+
+	kept := map[string]bool{}
+	for _, k := range keep {
+		kept[strings.Trim(filepath.ToSlash(k), "/")] = true
+	}
+
+	// a main package in the root is the binary of the module
+	if pkg, ok := p.Packages["."]; ok && pkg.Name == "main" {
+		for _, f := range pkg.Files {
+			plan.Moves = append(plan.Moves, Move{From: f.Path, To: path.Join("cmd", plan.Binary, f.Path), Reason: "main package in the root"})
+		}
+	}
+
+	for _, dir := range p.Dirs {
+		if dir == "." || strings.Contains(dir, "/") || standardDirs[dir] || kept[dir] || !project.HasPackages(p.Files, dir) {
+			continue
+		}
+
+		if pkg, ok := p.Packages[dir]; ok && pkg.Name == "main" {
+			plan.Moves = append(plan.Moves, Move{From: dir, To: path.Join("cmd", dir), Dir: true, Reason: "main package"})
+			continue
+		}
+		plan.Moves = append(plan.Moves, Move{From: dir, To: path.Join("internal", dir), Dir: true, Reason: "private package"})
+	}
+
+	for _, f := range p.Files {
+		if !strings.Contains(f, "/") && strings.HasSuffix(f, ".sh") {
+			plan.Moves = append(plan.Moves, Move{From: f, To: path.Join("scripts", f), Reason: "shell script"})
+		}
+	}
+
+	for _, m := range plan.Moves {
+		exists, err := vfs.Exists(fsys, filepath.FromSlash(m.To))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if exists {
+			return nil, fmt.Errorf("cannot move %s to %s: it already exists", m.From, m.To)
+		}
+	}
+
+	sort.SliceStable(plan.Moves, func(i, j int) bool { return plan.Moves[i].From < plan.Moves[j].From })
+	return plan, nil
+}
+
+// Apply moves the files and directories of the plan, then rewrites the
+// imports of the moved packages and the paths of the build files. The
+// rewrites are computed before anything is moved, so a file which cannot be
+// parsed leaves the project untouched, and the changes already made are
+// undone when a later one fails.
+func Apply(fsys vfs.FS, plan *Plan) (*Result, error) {
+	var rewrites []imports.Func
+	rootMain := false
+
+	for _, m := range plan.Moves {
+		if m.Dir {
+			rewrites = append(rewrites, imports.Prefix(plan.Module+"/"+m.From, plan.Module+"/"+m.To))
+		}
+		if strings.HasSuffix(m.From, ".go") && !strings.Contains(m.From, "/") {
+			rootMain = true
+		}
+	}
+
+	changes, err := imports.Changes(fsys, imports.Chain(rewrites...))
+	if err != nil {
+		return nil, err
+	}
+
+	var updates []imports.Change
+	for _, name := range buildFiles {
+		data, err := vfs.ReadFile(fsys, name)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		updated := updatePaths(string(data), plan, rootMain)
+		if updated == string(data) {
+			continue
+		}
+
+		info, err := fsys.Stat(name)
+		if err != nil {
+			return nil, err
+		}
+		updates = append(updates, imports.Change{Path: name, Data: []byte(updated), Mode: info.Mode().Perm()})
+	}
+
+	res := &Result{journal: vfs.NewJournal(fsys)}
+	if err := res.apply(plan, changes, updates); err != nil {
+		if undoErr := res.journal.Undo(); undoErr != nil {
+			return nil, fmt.Errorf("%w, undoing the adoption failed: %v", err, undoErr)
+		}
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// apply makes the moves, then writes the rewritten go files at their new path and the updated build files.
+func (r *Result) apply(plan *Plan, changes, updates []imports.Change) error {
+	for _, m := range plan.Moves {
+		if err := r.journal.MkdirAll(path.Dir(m.To)); err != nil {
+			return err
+		}
+		if err := r.journal.Rename(m.From, m.To); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range changes {
+		name := movedPath(plan, c.Path)
+		if err := r.journal.WriteFile(name, c.Data, c.Mode); err != nil {
+			return err
+		}
+		r.Rewritten = append(r.Rewritten, name)
+	}
+	sort.Strings(r.Rewritten)
+
+	for _, u := range updates {
+		if err := r.journal.WriteFile(u.Path, u.Data, u.Mode); err != nil {
+			return err
+		}
+		r.Updated = append(r.Updated, u.Path)
+	}
+
+	return nil
+}
+
+// Revert undoes the adoption, e.g. when the project does not build after it.
+func (r *Result) Revert() error {
+	return r.journal.Undo()
+}
+
+// movedPath returns the path of the file at name once the moves of the plan are made.
+func movedPath(plan *Plan, name string) string {
+	for _, m := range plan.Moves {
+		switch {
+		case name == m.From:
+			return m.To
+		case m.Dir && strings.HasPrefix(name, m.From+"/"):
+			return m.To + strings.TrimPrefix(name, m.From)
+		}
+	}
+	return name
+}
+
+// goCommandRoot matches the go build, run and install commands of the root package.
+var goCommandRoot = regexp.MustCompile(`(?m)(\bgo\s+(?:build|run|install)\b[^\n]*?\s)\./?(\s|$)`)
+
+// updatePaths replaces the moved paths referenced by a build file. When the
+// root main package moved, the go commands building the root build cmd/<binary>.
+func updatePaths(text string, plan *Plan, rootMain bool) string {
+	for _, m := range plan.Moves {
+		// the path is a whole word, possibly starting with ./ and followed by a subpath
+		re := regexp.MustCompile(`(?m)(^|[\s"'=(:,\[]|\./)` + regexp.QuoteMeta(m.From) + `([\s"'):;,\]/]|$)`)
+		for {
+			updated := re.ReplaceAllString(text, "${1}"+m.To+"${2}")
+			if updated == text {
+				break
+			}
+			text = updated
+		}
+	}
+
+	if rootMain {
+		text = goCommandRoot.ReplaceAllString(text, "${1}./cmd/"+plan.Binary+"${2}")
+	}
+
+	return text
+}
+
+// Build runs go build ./... in dir, to verify the project still builds after
+// the adoption. It needs the go toolchain in the PATH.
+func Build(dir string) error {
+	cmd := exec.Command("go", "build", "./...")
+	cmd.Dir = dir
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("go build ./...: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package changelog
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Add returns the changelog with the message added as an entry of the change
+// type subsection of the [Unreleased] section, e.g. added. The subsection is
+// created, in the order of Types, when the section has none. New subsections
+// are separated by blank lines when the other subsections are.
+func Add(data []byte, typ, message string) ([]byte, error) {
+	ti := typeIndex(typ)
+	if ti < 0 {
+		return nil, fmt.Errorf("invalid type: %s. Valid values are %s", typ, strings.ToLower(strings.Join(Types, ", ")))
+	}
+
+	message = strings.TrimSpace(message)
+	if message == "" || strings.ContainsAny(message, "\r\n") {
+		return nil, errors.New("the message is a single non empty line")
+	}
+	entry := "- " + message
+
+	lines := strings.Split(string(data), "\n")
+	start := -1
+	for i, l := range lines {
+		if unreleasedHeading.MatchString(l) {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return nil, errors.New("the changelog has no [Unreleased] section")
+	}
+
+	// body are the lines of the section, without its trailing blank lines
+	end := start + 1
+	for end < len(lines) && !strings.HasPrefix(lines[end], "## ") && !linkDefinition.MatchString(lines[end]) {
+		end++
+	}
+	bodyEnd := end
+	for bodyEnd > start+1 && strings.TrimSpace(lines[bodyEnd-1]) == "" {
+		bodyEnd--
+	}
+	body := append([]string(nil), lines[start+1:bodyEnd]...)
+
+	body = addEntry(body, ti, entry, blankSeparated(lines))
+
+	out := make([]string, 0, len(lines)+4)
+	out = append(out, lines[:start+1]...)
+	out = append(out, body...)
+	out = append(out, lines[bodyEnd:]...)
+	return []byte(strings.Join(out, "\n")), nil
+}
+
+// addEntry returns the body of the [Unreleased] section with the entry added
+// to the subsection of the type of index ti.
+func addEntry(body []string, ti int, entry string, blank bool) []string {
+	insert := func(at int, add ...string) []string {
+		return append(body[:at], append(add, body[at:]...)...)
+	}
+
+	for i, l := range body {
+		if !strings.HasPrefix(l, "### ") {
+			continue
+		}
+
+		switch t := typeIndex(strings.TrimSpace(l[4:])); {
+		case t == ti:
+			// after the last entry of the subsection, or its continuation lines
+			last := i
+			for j := i + 1; j < len(body) && !strings.HasPrefix(body[j], "#"); j++ {
+				if listItem.MatchString(body[j]) || strings.TrimSpace(body[j]) != "" && last == j-1 && last > i {
+					last = j
+				}
+			}
+			if last == i && blank {
+				return insert(i+1, "", entry)
+			}
+			return insert(last+1, entry)
+		case t > ti:
+			if blank {
+				return insert(i, "### "+Types[ti], "", entry, "")
+			}
+			return insert(i, "### "+Types[ti], entry)
+		}
+	}
+
+	if blank {
+		return append(body, "", "### "+Types[ti], "", entry)
+	}
+	return append(body, "### "+Types[ti], entry)
+}
+
+// blankSeparated reports whether the subsections of the changelog are
+// preceded by a blank line, as in the examples of Keep a Changelog, which is
+// the style of changelogs without subsection.
+func blankSeparated(lines []string) bool {
+	for i, l := range lines {
+		if strings.HasPrefix(l, "### ") && i > 0 {
+			return strings.TrimSpace(lines[i-1]) == ""
+		}
+	}
+	return true
+}
@@ -0,0 +1,142 @@
+// Package changelog reads and edits changelogs following Keep a Changelog,
+// see https://keepachangelog.com.
+//
+// A changelog has a # Changelog title, an [Unreleased] section followed by a
+// section per released version, newest first, e.g. ## [1.2.3] - 2021-09-01,
+// and the change type subsections of each of them, e.g. ### Added, listing
+// the changes. The link reference definitions of the sections come last.
+package changelog
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Types are the change types of the subsections, in the order of Keep a Changelog.
+var Types = []string{"Added", "Changed", "Deprecated", "Removed", "Fixed", "Security"}
+
+const (
+	// File is the name of the changelog in the project root.
+	File = "CHANGELOG.md"
+	// DateLayout is the layout of the release dates, ISO 8601.
+	DateLayout = "2006-01-02"
+)
+
+var (
+	// releaseHeading matches the heading of a section, the name is Unreleased
+	// or a version, followed by an optional date and [YANKED] mark.
+	releaseHeading = regexp.MustCompile(`^##\s*\[([^\]]+)\](?:\s*-\s*(\S+))?(\s*\[YANKED\])?\s*$`)
+	// unreleasedHeading matches the heading of the unreleased changes, which
+	// may carry a placeholder date, e.g. ## [Unreleased] - yyyy-mm-dd.
+	unreleasedHeading = regexp.MustCompile(`(?i)^##\s*\[unreleased\]`)
+	// versionHeading matches the heading of a released version, e.g. ## [1.2.3] - 2021-09-01.
+	versionHeading = regexp.MustCompile(`^##\s*\[v?([0-9][^\]]*)\]`)
+	// linkDefinition matches the link reference definitions, e.g. [1.2.3]: https://...
+	linkDefinition = regexp.MustCompile(`^\[([^\]]+)\]:\s*(\S+)\s*$`)
+	// compareURL matches the compare URL of the unreleased changes, e.g. https://github.com/o/r/compare/v1.2.3...HEAD.
+	compareURL = regexp.MustCompile(`^(.+)/compare/(.+)\.\.\.HEAD$`)
+	// listItem matches the entries of the sections.
+	listItem = regexp.MustCompile(`^\s*[-*+]\s+\S`)
+)
+
+// Changelog is the structure of a changelog.
+type Changelog struct {
+	// Title is the text of the first heading, Changelog, empty when the changelog has none.
+	Title    string
+	Releases []*Release
+	Links    []Link
+	// Strays are the lines of entries outside of a change type subsection.
+	Strays []int
+}
+
+// Release is a section of the changelog.
+type Release struct {
+	// Name is Unreleased or the version of the section.
+	Name string
+	// Date is the release date as written, empty for none.
+	Date   string
+	Yanked bool
+	// Line is the line of the heading, starting at 1.
+	Line     int
+	Sections []*Section
+}
+
+// Unreleased reports whether the release is the section of the unreleased changes.
+func (r *Release) Unreleased() bool {
+	return strings.EqualFold(r.Name, "unreleased")
+}
+
+// Section is a change type subsection of a release.
+type Section struct {
+	// Type is the change type as written, e.g. Added.
+	Type    string
+	Line    int
+	Entries []Entry
+}
+
+// Entry is a change of a section.
+type Entry struct {
+	Text string
+	Line int
+}
+
+// Link is a link reference definition.
+type Link struct {
+	Name string
+	URL  string
+	Line int
+}
+
+// Parse returns the structure of the changelog. Headings that are not
+// releases nor subsections are ignored, the lint reports them.
+func Parse(data []byte) *Changelog {
+	c := &Changelog{}
+	var release *Release
+	var section *Section
+
+	for i, l := range strings.Split(string(data), "\n") {
+		line := i + 1
+		switch {
+		case strings.HasPrefix(l, "# "):
+			if c.Title == "" {
+				c.Title = strings.TrimSpace(l[2:])
+			}
+		case strings.HasPrefix(l, "## "):
+			release, section = nil, nil
+			if m := releaseHeading.FindStringSubmatch(l); m != nil {
+				release = &Release{Name: m[1], Date: m[2], Yanked: m[3] != "", Line: line}
+				c.Releases = append(c.Releases, release)
+			}
+		case strings.HasPrefix(l, "### "):
+			section = nil
+			if release != nil {
+				section = &Section{Type: strings.TrimSpace(l[4:]), Line: line}
+				release.Sections = append(release.Sections, section)
+			}
+		case linkDefinition.MatchString(l):
+			m := linkDefinition.FindStringSubmatch(l)
+			c.Links = append(c.Links, Link{Name: m[1], URL: m[2], Line: line})
+			release, section = nil, nil
+		case listItem.MatchString(l) && !strings.HasPrefix(l, " ") && !strings.HasPrefix(l, "\t"):
+			switch {
+			case section != nil:
+				text := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(l), "-*+"))
+				section.Entries = append(section.Entries, Entry{Text: text, Line: line})
+			case release != nil:
+				c.Strays = append(c.Strays, line)
+			}
+		}
+	}
+	return c
+}
+
+// typeIndex returns the index of the change type in Types, matched case
+// insensitively, or -1 for an unknown type.
+func typeIndex(typ string) int {
+	for i, t := range Types {
+		if strings.EqualFold(t, typ) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,155 @@
+package changelog
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// commitHeader matches the header of a conventional commit, e.g. feat(api)!: add orders.
+	commitHeader = regexp.MustCompile(`^([A-Za-z]+)(?:\(([^)]*)\))?(!)?:\s+(\S.*)$`)
+	// breakingFooter matches the footer describing a breaking change.
+	breakingFooter = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE:\s*(\S.*)$`)
+	// issueFooter matches the footers referencing issues, e.g. Closes #12.
+	issueFooter = regexp.MustCompile(`(?mi)^(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?|refs?)[: ]\s*((?:#[0-9]+[, ]*)+)$`)
+	// issueRef matches the references to issues, e.g. #12.
+	issueRef = regexp.MustCompile(`(^|[\s(,])#([0-9]+)\b`)
+)
+
+// Commit is a conventional commit, see https://www.conventionalcommits.org.
+type Commit struct {
+	Hash        string
+	Type        string
+	Scope       string
+	Description string
+	// Breaking is the description of the breaking change, empty when the commit breaks nothing.
+	Breaking string
+	// Issues are the numbers of the issues referenced by the footers.
+	Issues []string
+}
+
+// ParseCommit parses the message of a conventional commit. It reports false
+// for messages that do not follow the specification.
+func ParseCommit(hash, message string) (Commit, bool) {
+	lines := strings.SplitN(strings.TrimSpace(message), "\n", 2)
+	m := commitHeader.FindStringSubmatch(strings.TrimSpace(lines[0]))
+	if m == nil {
+		return Commit{}, false
+	}
+
+	c := Commit{Hash: hash, Type: strings.ToLower(m[1]), Scope: m[2], Description: m[4]}
+	if m[3] != "" {
+		c.Breaking = c.Description
+	}
+	if len(lines) > 1 {
+		if b := breakingFooter.FindStringSubmatch(lines[1]); b != nil {
+			c.Breaking = b[1]
+		}
+		for _, f := range issueFooter.FindAllStringSubmatch(lines[1], -1) {
+			for _, ref := range issueRef.FindAllStringSubmatch(" "+f[1], -1) {
+				c.Issues = append(c.Issues, ref[2])
+			}
+		}
+	}
+	return c, true
+}
+
+// commitTypes are the change types of the commit types in the changelog, the
+// other commit types, e.g. docs or chore, are left out.
+var commitTypes = map[string]string{
+	"feat": "Added",
+	"fix":  "Fixed",
+	"perf": "Changed",
+}
+
+// Section returns the change type of the commit in the changelog, empty when
+// it is left out. Breaking changes are changes, whatever the commit type.
+func (c Commit) Section() string {
+	if c.Breaking != "" {
+		return "Changed"
+	}
+	return commitTypes[c.Type]
+}
+
+// Entry returns the changelog entry of the commit. The issues are linked with
+// issueURL, where {id} is replaced by the issue number, when it is not empty.
+func (c Commit) Entry(issueURL string) string {
+	text := c.Description
+	if c.Breaking != "" {
+		text = c.Breaking
+	}
+	if c.Scope != "" {
+		text = c.Scope + ": " + text
+	}
+	if c.Breaking != "" {
+		text = "**BREAKING:** " + text
+	}
+
+	for _, id := range c.Issues {
+		if !strings.Contains(text, "#"+id) {
+			text += " (#" + id + ")"
+		}
+	}
+	if issueURL == "" {
+		return text
+	}
+	return issueRef.ReplaceAllStringFunc(text, func(ref string) string {
+		m := issueRef.FindStringSubmatch(ref)
+		return m[1] + "[#" + m[2] + "](" + strings.ReplaceAll(issueURL, "{id}", m[2]) + ")"
+	})
+}
+
+// Generated are the changelog entries of commits by change type.
+type Generated struct {
+	// Entries are the entries by change type, in the order of the commits.
+	Entries map[string][]string
+	// Bump is the part of the version to bump for the commits: major for
+	// breaking changes, minor for features, patch for fixes and performance
+	// improvements, empty when the commits change nothing of the changelog.
+	Bump string
+}
+
+// Generate returns the changelog entries of the commits.
+func Generate(commits []Commit, issueURL string) Generated {
+	g := Generated{Entries: map[string][]string{}}
+	rank := map[string]int{"": 0, "patch": 1, "minor": 2, "major": 3}
+
+	for _, c := range commits {
+		typ := c.Section()
+		if typ == "" {
+			continue
+		}
+		g.Entries[typ] = append(g.Entries[typ], c.Entry(issueURL))
+
+		bump := "patch"
+		switch {
+		case c.Breaking != "":
+			bump = "major"
+		case c.Type == "feat":
+			bump = "minor"
+		}
+		if rank[bump] > rank[g.Bump] {
+			g.Bump = bump
+		}
+	}
+	return g
+}
+
+// Markdown returns the entries as the change type subsections of a changelog,
+// separated by blank lines as Add writes them.
+func (g Generated) Markdown() string {
+	var b strings.Builder
+	for _, t := range Types {
+		if len(g.Entries[t]) == 0 {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("### " + t + "\n\n")
+		for _, e := range g.Entries[t] {
+			b.WriteString("- " + e + "\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,119 @@
+package changelog
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCommit(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    Commit
+		ok      bool
+	}{
+		{
+			name:    "feature",
+			message: "feat: add orders export",
+			want:    Commit{Hash: "abc", Type: "feat", Description: "add orders export"},
+			ok:      true,
+		},
+		{
+			name:    "scope and upper case type",
+			message: "Fix(api): round the totals\n",
+			want:    Commit{Hash: "abc", Type: "fix", Scope: "api", Description: "round the totals"},
+			ok:      true,
+		},
+		{
+			name:    "breaking mark",
+			message: "feat(api)!: drop the v1 routes",
+			want:    Commit{Hash: "abc", Type: "feat", Scope: "api", Description: "drop the v1 routes", Breaking: "drop the v1 routes"},
+			ok:      true,
+		},
+		{
+			name:    "breaking footer",
+			message: "refactor: rename the config keys\n\nBREAKING CHANGE: the keys are snake cased",
+			want:    Commit{Hash: "abc", Type: "refactor", Description: "rename the config keys", Breaking: "the keys are snake cased"},
+			ok:      true,
+		},
+		{
+			name:    "issue footers",
+			message: "fix: totals\n\nThe rounding is done last.\n\nCloses #12, #14\nRefs: #3",
+			want:    Commit{Hash: "abc", Type: "fix", Description: "totals", Issues: []string{"12", "14", "3"}},
+			ok:      true,
+		},
+		{name: "not conventional", message: "Update README.md"},
+		{name: "no description", message: "feat: "},
+		{name: "no space", message: "feat:add orders"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseCommit("abc", tt.message)
+			if ok != tt.ok {
+				t.Fatalf("got ok %v, want %v", ok, tt.ok)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	commit := func(message string) Commit {
+		c, ok := ParseCommit("abc", message)
+		if !ok {
+			t.Fatalf("%q is not a conventional commit", message)
+		}
+		return c
+	}
+
+	tests := []struct {
+		name     string
+		commits  []Commit
+		issueURL string
+		want     Generated
+		markdown string
+	}{
+		{
+			name:    "left out",
+			commits: []Commit{commit("docs: usage"), commit("chore: bump cobra")},
+			want:    Generated{Entries: map[string][]string{}},
+		},
+		{
+			name:     "fixes",
+			commits:  []Commit{commit("fix: totals (#12)"), commit("perf(db): batch the inserts"), commit("test: totals")},
+			want:     Generated{Entries: map[string][]string{"Fixed": {"totals (#12)"}, "Changed": {"db: batch the inserts"}}, Bump: "patch"},
+			markdown: "### Changed\n\n- db: batch the inserts\n\n### Fixed\n\n- totals (#12)\n",
+		},
+		{
+			name:     "features and linked issues",
+			commits:  []Commit{commit("fix: totals\n\nCloses #12"), commit("feat: export")},
+			issueURL: "https://github.com/jane/orders/issues/{id}",
+			want: Generated{Entries: map[string][]string{
+				"Fixed": {"totals ([#12](https://github.com/jane/orders/issues/12))"},
+				"Added": {"export"},
+			}, Bump: "minor"},
+			markdown: "### Added\n\n- export\n\n### Fixed\n\n- totals ([#12](https://github.com/jane/orders/issues/12))\n",
+		},
+		{
+			name:     "breaking changes",
+			commits:  []Commit{commit("feat: export"), commit("fix(api)!: drop the v1 routes")},
+			want:     Generated{Entries: map[string][]string{"Added": {"export"}, "Changed": {"**BREAKING:** api: drop the v1 routes"}}, Bump: "major"},
+			markdown: "### Added\n\n- export\n\n### Changed\n\n- **BREAKING:** api: drop the v1 routes\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Generate(tt.commits, tt.issueURL)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+			if md := got.Markdown(); md != tt.markdown {
+				t.Errorf("got markdown\n%s\nwant\n%s", md, tt.markdown)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package changelog
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Cut returns the changelog with the changes of the [Unreleased] section
+// moved to a section of the version, e.g. 1.2.3, released on date, below a
+// new empty [Unreleased] section. The compare links at the bottom are updated
+// for the tag of the version, e.g. v1.2.3. When the changelog has no link of
+// the unreleased changes, they are added for the repository at repoURL, e.g.
+// https://github.com/jane/orders, if any.
+func Cut(data []byte, version, tag, date, repoURL string) ([]byte, error) {
+	lines := strings.Split(string(data), "\n")
+
+	start := -1
+	for i, l := range lines {
+		if unreleasedHeading.MatchString(l) {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return nil, errors.New("the changelog has no [Unreleased] section")
+	}
+
+	// the section ends at the next version or at the link definitions
+	changes, prev := false, ""
+	for _, l := range lines[start+1:] {
+		if m := versionHeading.FindStringSubmatch(l); m != nil {
+			prev = "v" + m[1]
+			break
+		}
+		if strings.HasPrefix(l, "## ") || linkDefinition.MatchString(l) {
+			break
+		}
+		changes = changes || listItem.MatchString(l)
+	}
+	if !changes {
+		return nil, errors.New("the [Unreleased] section of the changelog lists no changes")
+	}
+
+	out := make([]string, 0, len(lines)+4)
+	out = append(out, lines[:start]...)
+	out = append(out, "## [Unreleased]", "", fmt.Sprintf("## [%s] - %s", version, date))
+	out = append(out, lines[start+1:]...)
+
+	// the previous tag and repository are taken from the compare link of the unreleased changes
+	for i, l := range out {
+		m := linkDefinition.FindStringSubmatch(l)
+		if m == nil || !strings.EqualFold(m[1], "unreleased") {
+			continue
+		}
+
+		c := compareURL.FindStringSubmatch(m[2])
+		if c == nil {
+			return []byte(strings.Join(out, "\n")), nil
+		}
+
+		links := []string{
+			fmt.Sprintf("[Unreleased]: %s/compare/%s...HEAD", c[1], tag),
+			fmt.Sprintf("[%s]: %s/compare/%s...%s", version, c[1], c[2], tag),
+		}
+		out = append(out[:i], append(links, out[i+1:]...)...)
+		return []byte(strings.Join(out, "\n")), nil
+	}
+
+	if repoURL != "" {
+		link := fmt.Sprintf("[%s]: %s/releases/tag/%s", version, repoURL, tag)
+		if prev != "" {
+			link = fmt.Sprintf("[%s]: %s/compare/%s...%s", version, repoURL, prev, tag)
+		}
+
+		// the links are appended after a blank line, before the final newline
+		for len(out) > 0 && strings.TrimSpace(out[len(out)-1]) == "" {
+			out = out[:len(out)-1]
+		}
+		out = append(out, "", fmt.Sprintf("[Unreleased]: %s/compare/%s...HEAD", repoURL, tag), link, "")
+	}
+	return []byte(strings.Join(out, "\n")), nil
+}
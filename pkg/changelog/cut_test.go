@@ -0,0 +1,136 @@
+package changelog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCut(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		repoURL string
+		want    string
+		err     string
+	}{
+		{
+			name: "compare links updated",
+			data: `# Changelog
+
+## [Unreleased]
+
+### Added
+
+- Orders export.
+
+## [1.1.0] - 2021-08-01
+
+### Fixed
+
+- Totals.
+
+[Unreleased]: https://github.com/jane/orders/compare/v1.1.0...HEAD
+[1.1.0]: https://github.com/jane/orders/compare/v1.0.0...v1.1.0
+`,
+			want: `# Changelog
+
+## [Unreleased]
+
+## [1.2.0] - 2021-09-01
+
+### Added
+
+- Orders export.
+
+## [1.1.0] - 2021-08-01
+
+### Fixed
+
+- Totals.
+
+[Unreleased]: https://github.com/jane/orders/compare/v1.2.0...HEAD
+[1.2.0]: https://github.com/jane/orders/compare/v1.1.0...v1.2.0
+[1.1.0]: https://github.com/jane/orders/compare/v1.0.0...v1.1.0
+`,
+		},
+		{
+			name: "links added for the first release",
+			data: `# Changelog
+
+## [Unreleased] - yyyy-mm-dd
+
+- Initial release.
+`,
+			repoURL: "https://github.com/jane/orders",
+			want: `# Changelog
+
+## [Unreleased]
+
+## [1.2.0] - 2021-09-01
+
+- Initial release.
+
+[Unreleased]: https://github.com/jane/orders/compare/v1.2.0...HEAD
+[1.2.0]: https://github.com/jane/orders/releases/tag/v1.2.0
+`,
+		},
+		{
+			name: "links added after the previous version",
+			data: `## [Unreleased]
+
+- Export.
+
+## [v1.1.0] - 2021-08-01
+
+- Totals.
+`,
+			repoURL: "https://github.com/jane/orders",
+			want: `## [Unreleased]
+
+## [1.2.0] - 2021-09-01
+
+- Export.
+
+## [v1.1.0] - 2021-08-01
+
+- Totals.
+
+[Unreleased]: https://github.com/jane/orders/compare/v1.2.0...HEAD
+[1.2.0]: https://github.com/jane/orders/compare/v1.1.0...v1.2.0
+`,
+		},
+		{
+			name: "no links without repository",
+			data: "## [Unreleased]\n\n- Export.\n",
+			want: "## [Unreleased]\n\n## [1.2.0] - 2021-09-01\n\n- Export.\n",
+		},
+		{
+			name: "no unreleased section",
+			data: "# Changelog\n\n## [1.1.0] - 2021-08-01\n\n- Totals.\n",
+			err:  "no [Unreleased] section",
+		},
+		{
+			name: "no unreleased changes",
+			data: "# Changelog\n\n## [Unreleased]\n\n### Added\n\n## [1.1.0] - 2021-08-01\n\n- Totals.\n",
+			err:  "lists no changes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Cut([]byte(tt.data), "1.2.0", "v1.2.0", "2021-09-01", tt.repoURL)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("got error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
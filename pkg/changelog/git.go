@@ -0,0 +1,66 @@
+package changelog
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// LatestTag returns the nearest tag reachable from HEAD in the git
+// repository at dir, empty when there is none.
+func LatestTag(dir string) (string, error) {
+	// git describe fails without tag, they are listed first
+	out, err := git(dir, "tag", "--merged", "HEAD")
+	if err != nil || len(bytes.TrimSpace(out)) == 0 {
+		return "", err
+	}
+
+	out, err = git(dir, "describe", "--tags", "--abbrev=0")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Commits returns the conventional commits of the git repository at dir
+// after since, a tag or any revision, oldest first. The whole history of HEAD
+// is read when since is empty. The other commits are left out, as are merges.
+func Commits(dir, since string) ([]Commit, error) {
+	rev := "HEAD"
+	if since != "" {
+		rev = since + "..HEAD"
+	}
+
+	// the commits are separated by a record separator, the hash from the message by a NUL
+	out, err := git(dir, "log", "--reverse", "--no-merges", "--format=%h%x00%B%x1e", rev, "--")
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []Commit
+	for _, rec := range bytes.Split(out, []byte{0x1e}) {
+		parts := bytes.SplitN(bytes.TrimLeft(rec, "\n"), []byte{0}, 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if c, ok := ParseCommit(string(parts[0]), string(parts[1])); ok {
+			commits = append(commits, c)
+		}
+	}
+	return commits, nil
+}
+
+// git runs the git command in dir and returns its output.
+func git(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
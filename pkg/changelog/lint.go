@@ -0,0 +1,146 @@
+package changelog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dark-shade/go-setup/pkg/semver"
+)
+
+// Problem is a deviation of a changelog from Keep a Changelog.
+type Problem struct {
+	// Line is the line of the problem, starting at 1, 0 when it applies to the whole changelog.
+	Line    int
+	Message string
+}
+
+// Lint checks the structure of the changelog, the order of the versions and
+// of their release dates, which are not after today, and that every section
+// has a link definition when the changelog has link definitions.
+func Lint(data []byte, today time.Time) []Problem {
+	c := Parse(data)
+	var problems []Problem
+	report := func(line int, format string, args ...interface{}) {
+		problems = append(problems, Problem{Line: line, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if !strings.EqualFold(c.Title, "changelog") {
+		report(0, "the changelog has no # Changelog title")
+	}
+	for i, l := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(l, "## ") && !releaseHeading.MatchString(l) {
+			report(i+1, "section heading is not ## [Unreleased] nor ## [<version>] - <date>")
+		}
+	}
+	for _, line := range c.Strays {
+		report(line, "entry outside of a change type section, e.g. ### Added")
+	}
+
+	// above is the version above the current one, its version and date are
+	// zero when they are invalid
+	var above *Release
+	var aboveVersion *semver.Version
+	var aboveDate time.Time
+	names := map[string]bool{}
+	for i, r := range c.Releases {
+		if names[strings.ToLower(r.Name)] {
+			report(r.Line, "duplicate section [%s]", r.Name)
+		}
+		names[strings.ToLower(r.Name)] = true
+
+		lintSections(r, report)
+
+		if r.Unreleased() {
+			if i > 0 {
+				report(r.Line, "the [Unreleased] section comes before the released versions")
+			}
+			if r.Date != "" {
+				report(r.Line, "the [Unreleased] section has a date")
+			}
+			continue
+		}
+
+		var version *semver.Version
+		if v, err := semver.Parse(r.Name); err != nil {
+			report(r.Line, "[%s] is not a semantic version", r.Name)
+		} else {
+			version = &v
+		}
+		if version != nil && aboveVersion != nil && semver.Compare(*version, *aboveVersion) >= 0 {
+			report(r.Line, "version %s is not lower than the version %s above it", r.Name, above.Name)
+		}
+
+		date, err := time.Parse(DateLayout, r.Date)
+		switch {
+		case r.Date == "":
+			report(r.Line, "version %s has no release date", r.Name)
+		case err != nil:
+			report(r.Line, "release date %s of %s is not a YYYY-MM-DD date", r.Date, r.Name)
+		case date.After(today):
+			report(r.Line, "release date %s of %s is in the future", r.Date, r.Name)
+		case !aboveDate.IsZero() && date.After(aboveDate):
+			report(r.Line, "release date %s of %s is after the release date of %s above it", r.Date, r.Name, above.Name)
+		}
+
+		above, aboveVersion, aboveDate = r, version, date
+	}
+	if !names["unreleased"] {
+		report(0, "the changelog has no [Unreleased] section")
+	}
+
+	problems = append(problems, lintLinks(c, names)...)
+
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Line < problems[j].Line })
+	return problems
+}
+
+// lintSections checks the change types of the subsections of the release.
+func lintSections(r *Release, report func(int, string, ...interface{})) {
+	seen := map[int]bool{}
+	for _, s := range r.Sections {
+		t := typeIndex(s.Type)
+		switch {
+		case t < 0:
+			report(s.Line, "unknown change type %s, expected one of %s", s.Type, strings.Join(Types, ", "))
+			continue
+		case seen[t]:
+			report(s.Line, "duplicate ### %s section in [%s]", Types[t], r.Name)
+		}
+		seen[t] = true
+
+		if len(s.Entries) == 0 && !r.Unreleased() {
+			report(s.Line, "empty ### %s section in [%s]", Types[t], r.Name)
+		}
+	}
+}
+
+// lintLinks checks the link definitions, when the changelog has some: every
+// section has one, and the definitions named like a version have a section.
+func lintLinks(c *Changelog, names map[string]bool) []Problem {
+	if len(c.Links) == 0 {
+		return nil
+	}
+
+	var problems []Problem
+	links := map[string]bool{}
+	for _, l := range c.Links {
+		name := strings.ToLower(l.Name)
+		if links[name] {
+			problems = append(problems, Problem{Line: l.Line, Message: fmt.Sprintf("duplicate link definition [%s]", l.Name)})
+		}
+		links[name] = true
+
+		if _, err := semver.Parse(l.Name); (err == nil || name == "unreleased") && !names[name] {
+			problems = append(problems, Problem{Line: l.Line, Message: fmt.Sprintf("link definition [%s] without section", l.Name)})
+		}
+	}
+
+	for _, r := range c.Releases {
+		if !links[strings.ToLower(r.Name)] {
+			problems = append(problems, Problem{Line: r.Line, Message: fmt.Sprintf("section [%s] has no link definition", r.Name)})
+		}
+	}
+	return problems
+}
@@ -0,0 +1,201 @@
+package changelog
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLint(t *testing.T) {
+	today := time.Date(2021, time.September, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		data string
+		want []Problem
+	}{
+		{
+			name: "valid",
+			data: `# Changelog
+
+## [Unreleased]
+
+### Added
+
+## [1.1.0] - 2021-09-01
+
+### Added
+
+- Export.
+
+### Fixed
+
+- Totals.
+
+## [1.0.0] - 2021-08-01
+
+### Added
+
+- Initial release.
+
+[Unreleased]: https://github.com/jane/orders/compare/v1.1.0...HEAD
+[1.1.0]: https://github.com/jane/orders/compare/v1.0.0...v1.1.0
+[1.0.0]: https://github.com/jane/orders/releases/tag/v1.0.0
+`,
+		},
+		{
+			name: "entry outside of a section",
+			data: "# Changelog\n\n## [Unreleased]\n\n- Export.\n",
+			want: []Problem{{Line: 5, Message: "entry outside of a change type section, e.g. ### Added"}},
+		},
+		{
+			name: "structure",
+			data: `## [1.0.0] - 2021-08-01
+
+### Added
+
+- Export.
+
+### Improved
+
+- Speed.
+
+### Added
+
+- Import.
+
+### Fixed
+
+## Notes
+`,
+			want: []Problem{
+				{Line: 0, Message: "the changelog has no # Changelog title"},
+				{Line: 0, Message: "the changelog has no [Unreleased] section"},
+				{Line: 7, Message: "unknown change type Improved, expected one of Added, Changed, Deprecated, Removed, Fixed, Security"},
+				{Line: 11, Message: "duplicate ### Added section in [1.0.0]"},
+				{Line: 15, Message: "empty ### Fixed section in [1.0.0]"},
+				{Line: 17, Message: "section heading is not ## [Unreleased] nor ## [<version>] - <date>"},
+			},
+		},
+		{
+			name: "unreleased",
+			data: `# Changelog
+
+## [1.0.0] - 2021-08-01
+
+### Added
+
+- Export.
+
+## [Unreleased] - 2021-09-01
+`,
+			want: []Problem{
+				{Line: 9, Message: "the [Unreleased] section comes before the released versions"},
+				{Line: 9, Message: "the [Unreleased] section has a date"},
+			},
+		},
+		{
+			name: "versions and dates",
+			data: `# Changelog
+
+## [Unreleased]
+
+## [1.2.0] - 2021-10-01
+
+### Added
+
+- Export.
+
+## [1.3.0] - 2021-08-01
+
+### Added
+
+- Import.
+
+## [next] - 2021-07-01
+
+### Added
+
+- Totals.
+
+## [1.0.0] - 2021-08-15
+
+### Added
+
+- Orders.
+
+## [0.9.0] - 01/07/2021
+
+### Added
+
+- Beta.
+
+## [0.8.0]
+
+### Added
+
+- Alpha.
+
+## [0.8.0] - 2021-05-01
+
+### Added
+
+- Alpha.
+`,
+			want: []Problem{
+				{Line: 5, Message: "release date 2021-10-01 of 1.2.0 is in the future"},
+				{Line: 11, Message: "version 1.3.0 is not lower than the version 1.2.0 above it"},
+				{Line: 17, Message: "[next] is not a semantic version"},
+				{Line: 23, Message: "release date 2021-08-15 of 1.0.0 is after the release date of next above it"},
+				{Line: 29, Message: "release date 01/07/2021 of 0.9.0 is not a YYYY-MM-DD date"},
+				{Line: 35, Message: "version 0.8.0 has no release date"},
+				{Line: 41, Message: "duplicate section [0.8.0]"},
+				{Line: 41, Message: "version 0.8.0 is not lower than the version 0.8.0 above it"},
+			},
+		},
+		{
+			name: "links",
+			data: `# Changelog
+
+## [Unreleased]
+
+## [1.1.0] - 2021-09-01
+
+### Added
+
+- Export.
+
+## [1.0.0] - 2021-08-01
+
+### Added
+
+- Orders.
+
+[Unreleased]: https://github.com/jane/orders/compare/v1.1.0...HEAD
+[1.1.0]: https://github.com/jane/orders/compare/v1.0.0...v1.1.0
+[1.1.0]: https://github.com/jane/orders/compare/v1.0.0...v1.1.0
+[0.9.0]: https://github.com/jane/orders/releases/tag/v0.9.0
+[docs]: https://example.com/docs
+`,
+			want: []Problem{
+				{Line: 11, Message: "section [1.0.0] has no link definition"},
+				{Line: 19, Message: "duplicate link definition [1.1.0]"},
+				{Line: 20, Message: "link definition [0.9.0] without section"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Lint([]byte(tt.data), today)
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d problems %v, want %v", len(got), got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("problem %d: got %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
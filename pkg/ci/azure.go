@@ -0,0 +1,66 @@
+package ci
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dark-shade/go-setup/pkg/project"
+)
+
+// Azure renders the pipeline as an azure-pipelines.yml. Every job runs in a
+// golang container, and the caches are restored by the Cache task.
+func Azure(p Pipeline) []project.File {
+	var b strings.Builder
+	fmt.Fprintf(&b, `trigger:
+  branches:
+    include: [main]
+  tags:
+    include: ['v*']
+
+pr: [main]
+
+pool:
+  vmImage: ubuntu-latest
+
+variables:
+  GOPATH: $(Pipeline.Workspace)/.go
+  GOCACHE: $(Pipeline.Workspace)/.go/cache
+  goImage: golang:%s
+
+stages:
+`, imageTag(p.GoVersions[0]))
+
+	for _, stage := range p.Stages {
+		fmt.Fprintf(&b, "  - stage: %s\n", stage.Name)
+		for _, job := range stage.Jobs {
+			if job.OnTag {
+				b.WriteString("    condition: and(succeeded(), startsWith(variables['Build.SourceBranch'], 'refs/tags/v'))\n")
+				break
+			}
+		}
+		b.WriteString("    jobs:\n")
+		for _, job := range stage.Jobs {
+			fmt.Fprintf(&b, "      - job: %s\n", job.Name)
+			if job.Matrix {
+				b.WriteString("        strategy:\n          matrix:\n")
+				for _, v := range p.GoVersions {
+					fmt.Fprintf(&b, "            go_%s:\n              goImage: golang:%s\n", strings.ReplaceAll(v, ".", "_"), imageTag(v))
+				}
+			}
+			b.WriteString("        container: $[ variables['goImage'] ]\n        steps:\n")
+			if job.Publish {
+				b.WriteString("          - checkout: self\n            fetchDepth: 0\n")
+			}
+			fmt.Fprintf(&b, "          - task: Cache@2\n            inputs:\n              key: 'go | \"$(Agent.OS)\" | %s'\n              path: $(GOPATH)\n            displayName: Cache\n", p.Cache.KeyFile)
+			for _, step := range p.Steps(job) {
+				fmt.Fprintf(&b, "          - script: %s\n            displayName: %s\n", step.Run, step.Name)
+			}
+			for _, a := range job.Artifacts {
+				name := strings.TrimSuffix(a, "/")
+				fmt.Fprintf(&b, "          - publish: %s\n            artifact: %s\n", name, strings.ReplaceAll(name, ".", "-"))
+			}
+		}
+	}
+
+	return []project.File{{Path: "azure-pipelines.yml", Data: []byte(b.String())}}
+}
@@ -0,0 +1,74 @@
+// Package ci generates the continuous integration configuration of projects.
+//
+// A provider independent Pipeline is rendered to the configuration of each
+// provider. Every step of the generated pipelines invokes a target of the
+// Makefile, or of the file of the other task runner, generated by go-setup, so
+// the pipelines do the same as a local build.
+package ci
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dark-shade/go-setup/pkg/project"
+)
+
+// Config are the project settings the pipelines depend on.
+type Config struct {
+	// GoVersion is the go version of go.mod, e.g. 1.17.
+	GoVersion string
+	// Runner runs a target of the task runner of the project when followed by
+	// its name, empty for make.
+	Runner string
+	// InstallRunner is the shell command installing the runner, if it needs one.
+	InstallRunner string
+	// Goreleaser releases with the release target, which runs goreleaser,
+	// instead of uploading the binaries of the build target.
+	Goreleaser bool
+}
+
+// runner returns the command running the targets.
+func (c Config) runner() string {
+	if c.Runner == "" {
+		return "make"
+	}
+	return c.Runner
+}
+
+// GoVersions returns the go versions of the test matrix: the minimum version
+// of go.mod and the latest stable release.
+func (c Config) GoVersions() []string {
+	if c.GoVersion == "" {
+		return []string{"stable"}
+	}
+	return []string{c.GoVersion, "stable"}
+}
+
+// renderers render the pipeline for each provider.
+var renderers = map[string]func(Pipeline) []project.File{
+	"azure":    Azure,
+	"circleci": CircleCI,
+	"github":   GitHub,
+	"gitlab":   GitLab,
+	"jenkins":  Jenkins,
+}
+
+// Providers returns the names of the supported CI providers.
+func Providers() []string {
+	names := make([]string, 0, len(renderers))
+	for name := range renderers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Render returns the configuration files of the default pipeline for the provider.
+func Render(provider string, cfg Config) ([]project.File, error) {
+	render, ok := renderers[provider]
+	if !ok {
+		return nil, fmt.Errorf("invalid ci: %s. Valid values are %s", provider, strings.Join(Providers(), ", "))
+	}
+	return render(DefaultPipeline(cfg)), nil
+}
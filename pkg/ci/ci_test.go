@@ -0,0 +1,63 @@
+package ci
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "updates the golden files of testdata")
+
+// configs are the project settings the pipelines are rendered with, by variant.
+var configs = []struct {
+	name string
+	cfg  Config
+}{
+	{"make", Config{GoVersion: "1.17"}},
+	{"task", Config{GoVersion: "1.17", Runner: "go run github.com/go-task/task/v3/cmd/task@latest"}},
+	{"just", Config{GoVersion: "1.17", Runner: "$HOME/bin/just", InstallRunner: "curl -sSf https://just.systems/install.sh | bash -s -- --to $HOME/bin"}},
+	{"goreleaser", Config{GoVersion: "1.17", Goreleaser: true}},
+}
+
+func TestRender(t *testing.T) {
+	for _, provider := range Providers() {
+		for _, c := range configs {
+			t.Run(provider+"-"+c.name, func(t *testing.T) {
+				files, err := Render(provider, c.cfg)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				// the files are concatenated, each one after a line with its path
+				var got bytes.Buffer
+				for _, f := range files {
+					got.WriteString("-- " + f.Path + " --\n")
+					got.Write(f.Data)
+				}
+
+				golden := filepath.Join("testdata", provider+"-"+c.name+".golden")
+				if *update {
+					if err := os.WriteFile(golden, got.Bytes(), 0644); err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				want, err := os.ReadFile(golden)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(got.Bytes(), want) {
+					t.Errorf("%s differs from the rendered pipeline, run go test -update to accept the changes:\n%s", golden, got.String())
+				}
+			})
+		}
+	}
+}
+
+func TestRenderInvalid(t *testing.T) {
+	if _, err := Render("travis", Config{}); err == nil {
+		t.Error("got no error for an unknown provider")
+	}
+}
@@ -0,0 +1,59 @@
+package ci
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dark-shade/go-setup/pkg/project"
+)
+
+// CircleCI renders the pipeline as a CircleCI 2.1 configuration. The module
+// and build caches are saved with the checksum of the cache key file.
+func CircleCI(p Pipeline) []project.File {
+	var b strings.Builder
+	b.WriteString("version: 2.1\n\njobs:\n")
+
+	for _, stage := range p.Stages {
+		for _, job := range stage.Jobs {
+			fmt.Fprintf(&b, "  %s:\n", job.Name)
+			image := imageTag(p.GoVersions[0])
+			if job.Matrix {
+				fmt.Fprintf(&b, "    parameters:\n      version:\n        type: string\n        default: %s\n", quote(image))
+				image = "<< parameters.version >>"
+			}
+			fmt.Fprintf(&b, "    docker:\n      - image: golang:%s\n", image)
+			key := fmt.Sprintf("go-mod-{{ checksum \"%s\" }}", p.Cache.KeyFile)
+			fmt.Fprintf(&b, "    steps:\n      - checkout\n      - restore_cache:\n          keys:\n            - %s\n", key)
+			for _, step := range p.Steps(job) {
+				fmt.Fprintf(&b, "      - run:\n          name: %s\n          command: %s\n", step.Name, step.Run)
+			}
+			fmt.Fprintf(&b, "      - save_cache:\n          key: %s\n          paths:\n            - /go/pkg/mod\n            - /root/.cache/go-build\n", key)
+			for _, a := range job.Artifacts {
+				fmt.Fprintf(&b, "      - store_artifacts:\n          path: %s\n", strings.TrimSuffix(a, "/"))
+			}
+		}
+	}
+
+	b.WriteString("\nworkflows:\n  ci:\n    jobs:\n")
+	var requires []string
+	for _, stage := range p.Stages {
+		var names []string
+		for _, job := range stage.Jobs {
+			fmt.Fprintf(&b, "      - %s:\n", job.Name)
+			if job.Matrix {
+				fmt.Fprintf(&b, "          matrix:\n            parameters:\n              version: [%s]\n", strings.Join(imageTags(p.GoVersions), ", "))
+			}
+			if len(requires) > 0 {
+				fmt.Fprintf(&b, "          requires: [%s]\n", strings.Join(requires, ", "))
+			}
+			b.WriteString("          filters:\n            tags:\n              only: /^v.*/\n")
+			if job.OnTag {
+				b.WriteString("            branches:\n              ignore: /.*/\n")
+			}
+			names = append(names, job.Name)
+		}
+		requires = names
+	}
+
+	return []project.File{{Path: ".circleci/config.yml", Data: []byte(b.String())}}
+}
@@ -0,0 +1,110 @@
+package ci
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dark-shade/go-setup/pkg/project"
+)
+
+// GitHub renders the pipeline as a GitHub Actions workflow. The jobs of a
+// stage need the jobs of the previous stage, and the caches are restored by
+// actions/setup-go.
+func GitHub(p Pipeline) []project.File {
+	var b strings.Builder
+	b.WriteString(`name: CI
+
+on:
+  push:
+    branches: [main]
+    tags: ['v*']
+  pull_request:
+
+permissions:
+  contents: read
+
+jobs:
+`)
+
+	var needs []string
+	for _, stage := range p.Stages {
+		var names []string
+		for _, job := range stage.Jobs {
+			if needs != nil || len(names) > 0 {
+				b.WriteString("\n")
+			}
+			githubJob(&b, p, job, needs)
+			names = append(names, job.Name)
+		}
+		needs = names
+	}
+
+	return []project.File{{Path: ".github/workflows/ci.yml", Data: []byte(b.String())}}
+}
+
+// githubJob writes the job of the workflow.
+func githubJob(b *strings.Builder, p Pipeline, job Job, needs []string) {
+	fmt.Fprintf(b, "  %s:\n", job.Name)
+	if job.Matrix {
+		fmt.Fprintf(b, "    name: %s (go ${{ matrix.go }})\n", title(job.Name))
+	} else {
+		fmt.Fprintf(b, "    name: %s\n", title(job.Name))
+	}
+	if job.OnTag {
+		b.WriteString("    if: startsWith(github.ref, 'refs/tags/v')\n")
+	}
+	if len(needs) > 0 {
+		fmt.Fprintf(b, "    needs: [%s]\n", strings.Join(needs, ", "))
+	}
+	b.WriteString("    runs-on: ubuntu-latest\n")
+	if job.Release || job.Publish {
+		b.WriteString("    permissions:\n      contents: write\n")
+	}
+
+	if job.Matrix {
+		var versions []string
+		for _, v := range p.GoVersions {
+			versions = append(versions, quote(v))
+		}
+		fmt.Fprintf(b, "    strategy:\n      fail-fast: false\n      matrix:\n        go: [%s]\n", strings.Join(versions, ", "))
+	}
+
+	b.WriteString("    steps:\n      - uses: actions/checkout@v4\n")
+	if job.Publish {
+		b.WriteString("        with:\n          fetch-depth: 0\n")
+	}
+	b.WriteString("      - uses: actions/setup-go@v5\n        with:\n")
+	if job.Matrix {
+		b.WriteString("          go-version: ${{ matrix.go }}\n")
+	} else {
+		b.WriteString("          go-version-file: go.mod\n")
+	}
+	fmt.Fprintf(b, "          cache-dependency-path: %s\n", p.Cache.KeyFile)
+
+	for _, step := range p.Steps(job) {
+		fmt.Fprintf(b, "      - name: %s\n        run: %s\n", step.Name, step.Run)
+		if job.Publish {
+			b.WriteString("        env:\n          GITHUB_TOKEN: ${{ secrets.GITHUB_TOKEN }}\n")
+		}
+	}
+
+	if len(job.Artifacts) == 0 {
+		return
+	}
+
+	if job.Release {
+		b.WriteString("      - uses: softprops/action-gh-release@v2\n        with:\n          files: |\n")
+		for _, a := range job.Artifacts {
+			if strings.HasSuffix(a, "/") {
+				a += "*"
+			}
+			fmt.Fprintf(b, "            %s\n", a)
+		}
+		return
+	}
+
+	fmt.Fprintf(b, "      - uses: actions/upload-artifact@v4\n        with:\n          name: %s\n          path: |\n", job.Name)
+	for _, a := range job.Artifacts {
+		fmt.Fprintf(b, "            %s\n", a)
+	}
+}
@@ -0,0 +1,66 @@
+package ci
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dark-shade/go-setup/pkg/project"
+)
+
+// GitLab renders the pipeline as a .gitlab-ci.yml. The module and build
+// caches are kept in the project directory, where GitLab can cache them.
+func GitLab(p Pipeline) []project.File {
+	var b strings.Builder
+
+	b.WriteString("stages:\n")
+	for _, stage := range p.Stages {
+		fmt.Fprintf(&b, "  - %s\n", stage.Name)
+	}
+
+	fmt.Fprintf(&b, `
+variables:
+  GOPATH: $CI_PROJECT_DIR/.go
+  GOCACHE: $CI_PROJECT_DIR/.go/cache
+
+default:
+  image: golang:%s
+  cache:
+    key:
+      files:
+        - %s
+    paths:
+      - .go/pkg/mod/
+      - .go/cache/
+`, imageTag(p.GoVersions[0]), p.Cache.KeyFile)
+
+	for _, stage := range p.Stages {
+		for _, job := range stage.Jobs {
+			fmt.Fprintf(&b, "\n%s:\n  stage: %s\n", job.Name, stage.Name)
+
+			if job.Matrix {
+				fmt.Fprintf(&b, "  image: golang:$GO_VERSION\n  parallel:\n    matrix:\n      - GO_VERSION: [%s]\n", strings.Join(imageTags(p.GoVersions), ", "))
+			}
+			if job.OnTag {
+				b.WriteString("  rules:\n    - if: $CI_COMMIT_TAG =~ /^v/\n")
+			}
+			// the release is published with the GITLAB_TOKEN CI/CD variable
+			if job.Publish {
+				b.WriteString("  variables:\n    GIT_DEPTH: 0\n")
+			}
+
+			b.WriteString("  script:\n")
+			for _, step := range p.Steps(job) {
+				fmt.Fprintf(&b, "    - %s\n", step.Run)
+			}
+
+			if len(job.Artifacts) > 0 {
+				b.WriteString("  artifacts:\n    paths:\n")
+				for _, a := range job.Artifacts {
+					fmt.Fprintf(&b, "      - %s\n", a)
+				}
+			}
+		}
+	}
+
+	return []project.File{{Path: ".gitlab-ci.yml", Data: []byte(b.String())}}
+}
@@ -0,0 +1,83 @@
+package ci
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dark-shade/go-setup/pkg/project"
+)
+
+// Jenkins renders the pipeline as a declarative Jenkinsfile. Every job runs in
+// a golang docker agent, and the caches are kept in the workspace. Matrix jobs
+// are expanded to a parallel stage per go version, as declarative pipelines
+// can't nest a matrix in a parallel stage.
+func Jenkins(p Pipeline) []project.File {
+	var b strings.Builder
+	b.WriteString("pipeline {\n    agent none\n\n    stages {\n")
+
+	for _, stage := range p.Stages {
+		fmt.Fprintf(&b, "        stage('%s') {\n", title(stage.Name))
+		if len(stage.Jobs) == 1 && !stage.Jobs[0].Matrix {
+			jenkinsJob(&b, p, stage.Jobs[0], imageTag(p.GoVersions[0]), "            ")
+		} else {
+			b.WriteString("            parallel {\n")
+			for _, job := range stage.Jobs {
+				if !job.Matrix {
+					fmt.Fprintf(&b, "                stage('%s') {\n", title(job.Name))
+					jenkinsJob(&b, p, job, imageTag(p.GoVersions[0]), "                    ")
+					b.WriteString("                }\n")
+					continue
+				}
+				for _, v := range p.GoVersions {
+					fmt.Fprintf(&b, "                stage('%s (go %s)') {\n", title(job.Name), v)
+					jenkinsJob(&b, p, job, imageTag(v), "                    ")
+					b.WriteString("                }\n")
+				}
+			}
+			b.WriteString("            }\n")
+		}
+		b.WriteString("        }\n")
+	}
+
+	b.WriteString("    }\n}\n")
+	return []project.File{{Path: "Jenkinsfile", Data: []byte(b.String())}}
+}
+
+// jenkinsJob writes the body of the stage of the job run in the golang image
+// of tag, indented by indent.
+func jenkinsJob(b *strings.Builder, p Pipeline, job Job, tag string, indent string) {
+	w := func(format string, args ...interface{}) {
+		fmt.Fprintf(b, indent+format+"\n", args...)
+	}
+
+	if job.OnTag {
+		w("when { buildingTag() }")
+	}
+	w("agent { docker { image 'golang:%s' } }", tag)
+	w("environment {")
+	w("    GOPATH = \"${WORKSPACE}/.go\"")
+	w("    GOCACHE = \"${WORKSPACE}/.go/cache\"")
+	w("}")
+
+	w("steps {")
+	for _, step := range p.Steps(job) {
+		w("    sh '%s'", step.Run)
+	}
+	w("}")
+
+	if len(job.Artifacts) == 0 {
+		return
+	}
+	var patterns []string
+	for _, a := range job.Artifacts {
+		if strings.HasSuffix(a, "/") {
+			a += "**"
+		}
+		patterns = append(patterns, a)
+	}
+	w("post {")
+	w("    success {")
+	w("        archiveArtifacts artifacts: '%s'", strings.Join(patterns, ","))
+	w("    }")
+	w("}")
+}
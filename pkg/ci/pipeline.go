@@ -0,0 +1,133 @@
+package ci
+
+import (
+	"strings"
+)
+
+// Pipeline is the provider independent model of a CI pipeline, rendered by
+// the renderer of each provider.
+type Pipeline struct {
+	// GoVersions are the go versions of the matrix, the first one is used by
+	// the jobs without matrix.
+	GoVersions []string
+	Cache      Cache
+	// Runner runs a target of the task runner of the project when followed by its name, e.g. make.
+	Runner string
+	// Install is the shell command installing the runner, run before the
+	// targets of every job. It is empty when the runner needs no installation.
+	Install string
+	// Stages run one after the other, the jobs of a stage run in parallel.
+	Stages []Stage
+}
+
+// Cache is the cache of the go modules and build outputs.
+type Cache struct {
+	// KeyFile is the file whose checksum keys the cache.
+	KeyFile string
+}
+
+// Stage is a group of jobs running in parallel.
+type Stage struct {
+	Name string
+	Jobs []Job
+}
+
+// Job is a sequence of targets of the task runner run on a fresh checkout.
+type Job struct {
+	Name string
+	// Matrix runs the job for every go version of the pipeline.
+	Matrix bool
+	// OnTag runs the job only for version tags, v*.
+	OnTag bool
+	// Targets are the targets of the task runner run by the job.
+	Targets []string
+	// Artifacts are the paths kept after the job, directories end with a slash.
+	Artifacts []string
+	// Release publishes the artifacts as a release, where the provider supports it.
+	Release bool
+	// Publish is set when the targets publish the release themselves, e.g.
+	// with goreleaser. They need the whole git history and the token of the provider.
+	Publish bool
+}
+
+// DefaultPipeline returns the pipeline generated for a project: build, vet
+// and test across the go versions, coverage and lint, then the release of the
+// binaries for version tags.
+func DefaultPipeline(cfg Config) Pipeline {
+	release := Job{Name: "release", OnTag: true, Targets: []string{"build"}, Artifacts: []string{"bin/"}, Release: true}
+	if cfg.Goreleaser {
+		release = Job{Name: "release", OnTag: true, Targets: []string{"release"}, Publish: true}
+	}
+
+	return Pipeline{
+		GoVersions: cfg.GoVersions(),
+		Cache:      Cache{KeyFile: "go.mod"},
+		Runner:     cfg.runner(),
+		Install:    cfg.InstallRunner,
+		Stages: []Stage{
+			{
+				Name: "test",
+				Jobs: []Job{
+					{Name: "test", Matrix: true, Targets: []string{"build", "vet", "test"}},
+					{Name: "coverage", Targets: []string{"cover"}, Artifacts: []string{"coverage.out"}},
+					{Name: "lint", Targets: []string{"lint"}},
+				},
+			},
+			{
+				Name: "release",
+				Jobs: []Job{
+					release,
+				},
+			},
+		},
+	}
+}
+
+// Steps returns the shell commands of the job: the runner installation, if
+// any, and the invocation of each target.
+func (p Pipeline) Steps(job Job) []Step {
+	var steps []Step
+	if p.Install != "" {
+		steps = append(steps, Step{Name: "Install runner", Run: p.Install})
+	}
+	for _, target := range job.Targets {
+		steps = append(steps, Step{Name: title(target), Run: p.Runner + " " + target})
+	}
+	return steps
+}
+
+// Step is a shell command of a job.
+type Step struct {
+	Name string
+	Run  string
+}
+
+// title returns s with its first letter upper cased.
+func title(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// quote returns s as a single quoted YAML string.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// imageTag returns the tag of the golang docker image of a go version.
+func imageTag(version string) string {
+	if version == "stable" {
+		return "latest"
+	}
+	return version
+}
+
+// imageTags returns the quoted docker image tags of the go versions.
+func imageTags(versions []string) []string {
+	tags := make([]string, 0, len(versions))
+	for _, v := range versions {
+		tags = append(tags, quote(imageTag(v)))
+	}
+	return tags
+}
@@ -0,0 +1,153 @@
+// Package diff computes line based differences between texts, renders them
+// as unified diffs and merges concurrent changes made to the same text.
+package diff
+
+import (
+	"strings"
+)
+
+// Op is the operation of an edit.
+type Op int
+
+const (
+	// Equal keeps a line present in both texts.
+	Equal Op = iota
+	// Delete removes a line of the first text.
+	Delete
+	// Insert adds a line of the second text.
+	Insert
+)
+
+// Edit is a single line operation transforming a text into another.
+type Edit struct {
+	Op   Op
+	Line string
+}
+
+// Lines splits text into lines, each line keeps its trailing newline.
+func Lines(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Edits returns the shortest edit script transforming a into b.
+func Edits(a, b []string) []Edit {
+	ma := matches(a, b)
+
+	var edits []Edit
+	j := 0
+	for i, line := range a {
+		if ma[i] < 0 {
+			edits = append(edits, Edit{Op: Delete, Line: line})
+			continue
+		}
+
+		for ; j < ma[i]; j++ {
+			edits = append(edits, Edit{Op: Insert, Line: b[j]})
+		}
+		edits = append(edits, Edit{Op: Equal, Line: line})
+		j++
+	}
+
+	for ; j < len(b); j++ {
+		edits = append(edits, Edit{Op: Insert, Line: b[j]})
+	}
+
+	return edits
+}
+
+// EqualLines reports whether a and b have the same lines.
+func EqualLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// matches returns, for each line of a, the index of the line of b it is kept
+// as in a longest common subsequence of a and b, or -1 when it is deleted.
+// It uses the O(ND) algorithm of Eugene W. Myers.
+func matches(a, b []string) []int {
+	n, m := len(a), len(b)
+	ma := make([]int, n)
+	for i := range ma {
+		ma[i] = -1
+	}
+
+	max := n + m
+	if max == 0 {
+		return ma
+	}
+
+	// v[k+offset] is the furthest x reached on diagonal k
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	var trace [][]int
+
+search:
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v...))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[k-1+offset] < v[k+1+offset]) {
+				x = v[k+1+offset]
+			} else {
+				x = v[k-1+offset] + 1
+			}
+
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[k+offset] = x
+
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// walk the trace backwards to find the diagonals, i.e. the matched lines
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0 && (x > 0 || y > 0); d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[k-1+offset] < v[k+1+offset]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+
+		prevX := v[prevK+offset]
+		prevY := prevX - prevK
+		if d == 0 {
+			prevX, prevY = 0, 0
+		}
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ma[x] = y
+		}
+
+		x, y = prevX, prevY
+	}
+
+	return ma
+}
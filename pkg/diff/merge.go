@@ -0,0 +1,69 @@
+package diff
+
+// Labels name the sides of a conflict in the conflict markers.
+type Labels struct {
+	Ours   string
+	Theirs string
+}
+
+// Merge merges the changes made to base in ours and theirs, like diff3 does.
+// Changes made on a single side, or identically on both sides, are applied.
+// Overlapping different changes are conflicts: both versions are kept between
+// conflict markers, and conflicts counts them.
+func Merge(base, ours, theirs []string, labels Labels) (merged []string, conflicts int) {
+	mo := matches(base, ours)
+	mt := matches(base, theirs)
+
+	i, o, t := 0, 0, 0
+	for {
+		// the next base line kept on both sides is stable, the lines before it form a chunk
+		j := i
+		for j < len(base) && (mo[j] < 0 || mt[j] < 0) {
+			j++
+		}
+
+		oEnd, tEnd := len(ours), len(theirs)
+		if j < len(base) {
+			oEnd, tEnd = mo[j], mt[j]
+		}
+
+		chunk, conflict := mergeChunk(base[i:j], ours[o:oEnd], theirs[t:tEnd], labels)
+		merged = append(merged, chunk...)
+		if conflict {
+			conflicts++
+		}
+
+		if j == len(base) {
+			return merged, conflicts
+		}
+
+		merged = append(merged, base[j])
+		i, o, t = j+1, oEnd+1, tEnd+1
+	}
+}
+
+// mergeChunk resolves a chunk of base changed into ours and theirs.
+func mergeChunk(base, ours, theirs []string, labels Labels) ([]string, bool) {
+	switch {
+	case EqualLines(ours, theirs), EqualLines(theirs, base):
+		return ours, false
+	case EqualLines(ours, base):
+		return theirs, false
+	}
+
+	chunk := []string{"<<<<<<< " + labels.Ours + "\n"}
+	chunk = append(chunk, terminated(ours)...)
+	chunk = append(chunk, "=======\n")
+	chunk = append(chunk, terminated(theirs)...)
+	chunk = append(chunk, ">>>>>>> "+labels.Theirs+"\n")
+
+	return chunk, true
+}
+
+// terminated returns lines where the last line ends with a newline, so conflict markers start on their own line.
+func terminated(lines []string) []string {
+	if n := len(lines); n > 0 && lines[n-1][len(lines[n-1])-1] != '\n' {
+		lines = append(append([]string(nil), lines[:n-1]...), lines[n-1]+"\n")
+	}
+	return lines
+}
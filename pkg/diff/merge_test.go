@@ -0,0 +1,114 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	labels := Labels{Ours: "local", Theirs: "template"}
+
+	tests := []struct {
+		name      string
+		base      string
+		ours      string
+		theirs    string
+		want      string
+		conflicts int
+	}{
+		{
+			name:   "unchanged",
+			base:   "a\nb\nc\n",
+			ours:   "a\nb\nc\n",
+			theirs: "a\nb\nc\n",
+			want:   "a\nb\nc\n",
+		},
+		{
+			name:   "changed by ours",
+			base:   "a\nb\nc\n",
+			ours:   "a\nB\nc\n",
+			theirs: "a\nb\nc\n",
+			want:   "a\nB\nc\n",
+		},
+		{
+			name:   "changed by theirs",
+			base:   "a\nb\nc\n",
+			ours:   "a\nb\nc\n",
+			theirs: "a\nb\nC\n",
+			want:   "a\nb\nC\n",
+		},
+		{
+			name:   "separate changes",
+			base:   "a\nb\nc\nd\ne\n",
+			ours:   "A\nb\nc\nd\ne\n",
+			theirs: "a\nb\nc\nd\nE\n",
+			want:   "A\nb\nc\nd\nE\n",
+		},
+		{
+			name:   "same change on both sides",
+			base:   "a\nb\nc\n",
+			ours:   "a\nx\nc\n",
+			theirs: "a\nx\nc\n",
+			want:   "a\nx\nc\n",
+		},
+		{
+			name:   "insertions and deletions",
+			base:   "a\nb\nc\nd\n",
+			ours:   "a\nnew\nb\nc\nd\n",
+			theirs: "a\nb\nc\n",
+			want:   "a\nnew\nb\nc\n",
+		},
+		{
+			name:      "conflict",
+			base:      "a\nb\nc\n",
+			ours:      "a\nours\nc\n",
+			theirs:    "a\ntheirs\nc\n",
+			want:      "a\n<<<<<<< local\nours\n=======\ntheirs\n>>>>>>> template\nc\n",
+			conflicts: 1,
+		},
+		{
+			name:      "conflict deleted on one side",
+			base:      "a\nb\nc\n",
+			ours:      "a\nc\n",
+			theirs:    "a\nchanged\nc\n",
+			want:      "a\n<<<<<<< local\n=======\nchanged\n>>>>>>> template\nc\n",
+			conflicts: 1,
+		},
+		{
+			name:      "conflicts counted",
+			base:      "a\nb\nc\nd\ne\n",
+			ours:      "1\nb\nc\nd\n5\n",
+			theirs:    "one\nb\nc\nd\nfive\n",
+			want:      "<<<<<<< local\n1\n=======\none\n>>>>>>> template\nb\nc\nd\n<<<<<<< local\n5\n=======\nfive\n>>>>>>> template\n",
+			conflicts: 2,
+		},
+		{
+			name:      "conflict without final newline",
+			base:      "a\nb",
+			ours:      "a\nours",
+			theirs:    "a\ntheirs",
+			want:      "a\n<<<<<<< local\nours\n=======\ntheirs\n>>>>>>> template\n",
+			conflicts: 1,
+		},
+		{
+			name:   "empty base",
+			base:   "",
+			ours:   "",
+			theirs: "a\n",
+			want:   "a\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, conflicts := Merge(Lines(tt.base), Lines(tt.ours), Lines(tt.theirs), labels)
+
+			if got := strings.Join(merged, ""); got != tt.want {
+				t.Errorf("got merged\n%s\nwant\n%s", got, tt.want)
+			}
+			if conflicts != tt.conflicts {
+				t.Errorf("got %d conflicts, want %d", conflicts, tt.conflicts)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultContext is the number of unchanged lines shown around the changes of a unified diff.
+const DefaultContext = 3
+
+// Unified returns the unified diff transforming a into b, with context
+// unchanged lines around each change. It is empty when a and b are equal.
+func Unified(aName, bName string, a, b []string, context int) string {
+	edits := Edits(a, b)
+
+	var out strings.Builder
+	for _, h := range hunks(edits, context) {
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n", aName, bName)
+		}
+
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(h.aStart, h.aLines), hunkRange(h.bStart, h.bLines))
+		for _, e := range edits[h.start:h.end] {
+			prefix := " "
+			switch e.Op {
+			case Delete:
+				prefix = "-"
+			case Insert:
+				prefix = "+"
+			}
+
+			out.WriteString(prefix + e.Line)
+			if !strings.HasSuffix(e.Line, "\n") {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+	}
+
+	return out.String()
+}
+
+// hunk is a range of edits along with the lines it covers in both texts.
+type hunk struct {
+	start, end     int
+	aStart, aLines int
+	bStart, bLines int
+}
+
+// hunks groups the changes of edits, surrounded by context equal edits.
+// Changes separated by at most 2*context equal edits share a hunk.
+func hunks(edits []Edit, context int) []hunk {
+	var list []hunk
+
+	i := 0
+	for i < len(edits) {
+		// find the next change
+		for i < len(edits) && edits[i].Op == Equal {
+			i++
+		}
+		if i == len(edits) {
+			break
+		}
+
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		// merge with the previous hunk when the contexts overlap
+		if n := len(list); n > 0 && start <= list[n-1].end {
+			start = list[n-1].start
+			list = list[:n-1]
+		}
+
+		// extend until context equal edits follow the last change
+		end := i
+		for end < len(edits) {
+			if edits[end].Op != Equal {
+				end++
+				i = end
+				continue
+			}
+			if end-i >= context {
+				break
+			}
+			end++
+		}
+
+		list = append(list, newHunk(edits, start, end))
+		i = end
+	}
+
+	return list
+}
+
+// newHunk returns the hunk of edits[start:end] with its line numbers.
+func newHunk(edits []Edit, start, end int) hunk {
+	h := hunk{start: start, end: end, aStart: 1, bStart: 1}
+
+	for _, e := range edits[:start] {
+		if e.Op != Insert {
+			h.aStart++
+		}
+		if e.Op != Delete {
+			h.bStart++
+		}
+	}
+
+	for _, e := range edits[start:end] {
+		if e.Op != Insert {
+			h.aLines++
+		}
+		if e.Op != Delete {
+			h.bLines++
+		}
+	}
+
+	return h
+}
+
+// hunkRange formats the range of a hunk header, empty ranges start at the line before.
+func hunkRange(start, lines int) string {
+	switch lines {
+	case 0:
+		return fmt.Sprintf("%d,0", start-1)
+	case 1:
+		return fmt.Sprintf("%d", start)
+	default:
+		return fmt.Sprintf("%d,%d", start, lines)
+	}
+}
@@ -0,0 +1,190 @@
+// Package docker generates the container image build of projects.
+//
+// The Dockerfile is a multi-stage build: the binaries are built with the go
+// version of go.mod in the golang image, with the module download and build
+// caches mounted, and copied to a distroless or scratch image running as a
+// non-root user.
+package docker
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/dark-shade/go-setup/pkg/project"
+)
+
+// Runtimes are the supported runtime images, the first one is the default.
+var Runtimes = []string{"distroless", "scratch"}
+
+// nonRoot is the uid and gid of the nonroot user of the distroless images.
+const nonRoot = "65532:65532"
+
+// Config are the project settings the image build depends on.
+type Config struct {
+	// GoVersion is the go version of go.mod, e.g. 1.17.
+	GoVersion string
+	// Binaries are the main packages of the project, the first one is built by default.
+	Binaries []project.Binary
+	// CGO enables cgo, the binaries are then linked against the glibc of the runtime image.
+	CGO bool
+	// Runtime is the runtime image, one of Runtimes. Empty is the default one.
+	Runtime string
+	// Port is the port exposed by services, 0 for none.
+	Port int
+	// HealthCheck are the arguments the binary is run with by the HEALTHCHECK, none for no health check.
+	HealthCheck []string
+	// VersionVar is the variable set to the VERSION build argument with -ldflags -X, empty for none.
+	VersionVar string
+}
+
+// Validate reports whether the runtime and cgo settings of the configuration are valid.
+func (c Config) Validate() error {
+	if c.Runtime != "" && !validRuntime(c.Runtime) {
+		return fmt.Errorf("invalid runtime: %s. Valid values are %s", c.Runtime, strings.Join(Runtimes, ", "))
+	}
+	if c.CGO && c.Runtime == "scratch" {
+		return errors.New("cgo binaries need the C library of the distroless runtime, scratch has none")
+	}
+	return nil
+}
+
+func validRuntime(runtime string) bool {
+	for _, r := range Runtimes {
+		if r == runtime {
+			return true
+		}
+	}
+	return false
+}
+
+// Render returns the Dockerfile and the .dockerignore of the configuration.
+func Render(cfg Config) ([]project.File, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if len(cfg.Binaries) == 0 {
+		return nil, errors.New("no main package to build")
+	}
+	if cfg.Runtime == "" {
+		cfg.Runtime = Runtimes[0]
+	}
+
+	var b bytes.Buffer
+	if err := dockerfile.Execute(&b, newData(cfg)); err != nil {
+		return nil, err
+	}
+
+	return []project.File{
+		{Path: "Dockerfile", Data: b.Bytes()},
+		{Path: ".dockerignore", Data: []byte(dockerignore)},
+	}, nil
+}
+
+// data is the data of the Dockerfile template.
+type data struct {
+	Config
+	// Binary is the default binary, Package the package built for it.
+	Binary  string
+	Package string
+	Image   string
+	User    string
+	// HealthCheck is the exec form of the HEALTHCHECK command.
+	HealthCheck string
+}
+
+func newData(cfg Config) data {
+	d := data{Config: cfg, Binary: cfg.Binaries[0].Name, Package: cfg.Binaries[0].Package, User: nonRoot}
+
+	// the binaries of cmd/ are selected with the BINARY build argument
+	if allCmd(cfg.Binaries) {
+		d.Package = "./cmd/${BINARY}"
+	}
+
+	switch {
+	case cfg.Runtime == "scratch":
+		d.Image = "scratch"
+	case cfg.CGO:
+		d.Image = "gcr.io/distroless/base-debian12:nonroot"
+	default:
+		d.Image = "gcr.io/distroless/static-debian12:nonroot"
+	}
+
+	if len(cfg.HealthCheck) > 0 {
+		args := []string{`"/app"`}
+		for _, a := range cfg.HealthCheck {
+			args = append(args, fmt.Sprintf("%q", a))
+		}
+		d.HealthCheck = "[" + strings.Join(args, ", ") + "]"
+	}
+
+	return d
+}
+
+// allCmd reports whether every binary is a main package of cmd/.
+func allCmd(binaries []project.Binary) bool {
+	for _, b := range binaries {
+		if b.Package != "./cmd/"+b.Name {
+			return false
+		}
+	}
+	return true
+}
+
+var dockerfile = template.Must(template.New("Dockerfile").Parse(`# syntax=docker/dockerfile:1
+
+ARG GO_VERSION={{.GoVersion}}
+
+FROM golang:${GO_VERSION} AS build
+WORKDIR /src
+
+# the modules are downloaded first, so the layer is reused until go.mod changes
+COPY go.mod go.sum* ./
+RUN --mount=type=cache,target=/go/pkg/mod go mod download
+
+COPY . .
+{{- if eq .Package "./cmd/${BINARY}"}}
+
+# the main package of cmd/ to build, one of:{{range .Binaries}} {{.Name}}{{end}}
+ARG BINARY={{.Binary}}
+{{- end}}
+{{- if .VersionVar}}
+
+# the version of the binary, git is not in the build context, e.g. --build-arg VERSION=$(cat VERSION)
+ARG VERSION=dev
+{{- end}}
+RUN --mount=type=cache,target=/go/pkg/mod \
+    --mount=type=cache,target=/root/.cache/go-build \
+    CGO_ENABLED={{if .CGO}}1{{else}}0{{end}} GOOS=linux go build -trimpath -ldflags="-s -w{{if .VersionVar}} -X {{.VersionVar}}=${VERSION}{{end}}" -o /out/app {{.Package}}
+
+FROM {{.Image}}
+{{- if eq .Runtime "scratch"}}
+COPY --from=build /etc/ssl/certs/ca-certificates.crt /etc/ssl/certs/
+{{- end}}
+COPY --from=build /out/app /app
+USER {{.User}}
+{{- if .Port}}
+EXPOSE {{.Port}}
+{{- end}}
+{{- if .HealthCheck}}
+HEALTHCHECK --interval=30s --timeout=5s --start-period=5s CMD {{.HealthCheck}}
+{{- end}}
+ENTRYPOINT ["/app"]
+`))
+
+// dockerignore keeps the build context to the sources of the binaries.
+const dockerignore = `# version control and go-setup metadata
+.git
+.go-setup
+
+# build outputs
+bin/
+coverage.out
+*.test
+
+# files not needed by the build
+Dockerfile
+.dockerignore
+`
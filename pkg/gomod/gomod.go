@@ -0,0 +1,126 @@
+// Package gomod reads the go.mod file of the projects go-setup works on.
+//
+// Only the directives go-setup needs are supported, so it does not depend on
+// golang.org/x/mod.
+package gomod
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/dark-shade/go-setup/pkg/vfs"
+)
+
+// ErrNoModule is returned when no go.mod file is found.
+var ErrNoModule = errors.New("go.mod file not found in the directory or any of its parents")
+
+// File is the parsed content of a go.mod file.
+type File struct {
+	// Module is the module path.
+	Module string
+	// Go is the go version, e.g. 1.17.
+	Go string
+}
+
+// Parse parses the module and go directives of a go.mod file.
+func Parse(data []byte) (*File, error) {
+	f := &File{}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+
+		switch fields[0] {
+		case "module":
+			f.Module = unquote(fields[1])
+		case "go":
+			f.Go = fields[1]
+		}
+	}
+
+	if f.Module == "" {
+		return nil, errors.New("go.mod: missing module directive")
+	}
+
+	return f, nil
+}
+
+// unquote removes the quotes of a quoted module path.
+func unquote(s string) string {
+	if u, err := strconv.Unquote(s); err == nil {
+		return u
+	}
+	return s
+}
+
+// Read parses the go.mod file of dir.
+func Read(fsys vfs.FS, dir string) (*File, error) {
+	data, err := vfs.ReadFile(fsys, filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// FindRoot returns the first directory containing a go.mod file, starting at
+// dir and walking up its parents.
+func FindRoot(fsys vfs.FS, dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		exists, err := vfs.Exists(fsys, filepath.Join(dir, "go.mod"))
+		if err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+		if exists {
+			return dir, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ErrNoModule
+		}
+		dir = parent
+	}
+}
+
+// SetModule returns the go.mod file data with the module path of its module
+// directive replaced by module. Comments and other directives are kept.
+func SetModule(data []byte, module string) ([]byte, error) {
+	lines := strings.SplitAfter(string(data), "\n")
+
+	for i, line := range lines {
+		code := line
+		if j := strings.Index(code, "//"); j >= 0 {
+			code = code[:j]
+		}
+
+		fields := strings.Fields(code)
+		if len(fields) != 2 || fields[0] != "module" {
+			continue
+		}
+
+		replacement := module
+		if strings.HasPrefix(fields[1], `"`) || strings.HasPrefix(fields[1], "`") {
+			replacement = strconv.Quote(module)
+		}
+
+		j := strings.Index(line, fields[1])
+		lines[i] = line[:j] + replacement + line[j+len(fields[1]):]
+		return []byte(strings.Join(lines, "")), nil
+	}
+
+	return nil, errors.New("go.mod: missing module directive")
+}
@@ -0,0 +1,195 @@
+// Package imports rewrites the import paths of go source files.
+//
+// The import paths are found with go/parser and replaced as text at their
+// offsets, so comments and build constraints stay where they are, and the
+// result is formatted with go/format, which also sorts the imports again.
+package imports
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dark-shade/go-setup/pkg/vfs"
+)
+
+// Func returns the new import path of an import path, and whether it changes.
+type Func func(importPath string) (string, bool)
+
+// skippedDirs are never rewritten. .go-setup holds the base content of the
+// generated files, which is updated along with the manifest.
+var skippedDirs = map[string]bool{".git": true, ".go-setup": true, "vendor": true, "testdata": true, "node_modules": true}
+
+// SkipDir reports whether the go files below the directory name of fsys are
+// left alone: the directories of other tools, and nested modules, whose
+// imports belong to another module.
+func SkipDir(fsys vfs.FS, name string) (bool, error) {
+	if skippedDirs[filepath.Base(name)] {
+		return true, nil
+	}
+	if filepath.Clean(name) == "." {
+		return false, nil
+	}
+
+	nested, err := vfs.Exists(fsys, filepath.Join(name, "go.mod"))
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	return nested, nil
+}
+
+// Prefix returns a Func replacing the import path old, and the import paths
+// below it, with the same paths below new.
+func Prefix(old, new string) Func {
+	return func(importPath string) (string, bool) {
+		switch {
+		case importPath == old:
+			return new, true
+		case strings.HasPrefix(importPath, old+"/"):
+			return new + strings.TrimPrefix(importPath, old), true
+		}
+		return importPath, false
+	}
+}
+
+// Chain returns a Func applying the first of fns changing an import path.
+func Chain(fns ...Func) Func {
+	return func(importPath string) (string, bool) {
+		for _, fn := range fns {
+			if p, ok := fn(importPath); ok {
+				return p, true
+			}
+		}
+		return importPath, false
+	}
+}
+
+// Rewrite rewrites the imports of the go source src with fn. updated is nil
+// when no import changes.
+func Rewrite(src []byte, fn Func) (updated []byte, err error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ImportsOnly|parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	type edit struct {
+		start, end int
+		text       string
+	}
+
+	var edits []edit
+	for _, imp := range f.Imports {
+		old, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+
+		if p, ok := fn(old); ok && p != old {
+			edits = append(edits, edit{
+				start: fset.Position(imp.Path.Pos()).Offset,
+				end:   fset.Position(imp.Path.End()).Offset,
+				text:  strconv.Quote(p),
+			})
+		}
+	}
+
+	if len(edits) == 0 {
+		return nil, nil
+	}
+
+	var buf bytes.Buffer
+	last := 0
+	for _, e := range edits {
+		buf.Write(src[last:e.start])
+		buf.WriteString(e.text)
+		last = e.end
+	}
+	buf.Write(src[last:])
+
+	return format.Source(buf.Bytes())
+}
+
+// Change is the rewritten content of a go file.
+type Change struct {
+	// Path is the slash separated path of the file.
+	Path string
+	Data []byte
+	Mode fs.FileMode
+}
+
+// Changes returns the changes rewriting the imports of every go file of fsys
+// with fn, whatever its build constraints, test files included, sorted by
+// path. The directories of SkipDir are skipped. Nothing is written, so a file
+// which cannot be parsed fails the rewrite before any file changes.
+func Changes(fsys vfs.FS, fn Func) ([]Change, error) {
+	var changes []Change
+
+	err := vfs.Walk(fsys, ".", func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			skip, err := SkipDir(fsys, name)
+			if err != nil {
+				return err
+			}
+			if skip {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !strings.HasSuffix(name, ".go") || !info.Mode().IsRegular() {
+			return nil
+		}
+
+		src, err := vfs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+
+		updated, err := Rewrite(src, fn)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if updated != nil {
+			changes = append(changes, Change{Path: filepath.ToSlash(name), Data: updated, Mode: info.Mode().Perm()})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// RewriteFS rewrites the imports of every go file of fsys with fn, once every
+// file is parsed, see Changes. It returns the slash separated paths of the
+// rewritten files.
+func RewriteFS(fsys vfs.FS, fn Func) ([]string, error) {
+	changes, err := Changes(fsys, fn)
+	if err != nil {
+		return nil, err
+	}
+
+	var rewritten []string
+	for _, c := range changes {
+		if err := vfs.WriteFile(fsys, filepath.FromSlash(c.Path), c.Data, c.Mode); err != nil {
+			return nil, err
+		}
+		rewritten = append(rewritten, c.Path)
+	}
+	return rewritten, nil
+}
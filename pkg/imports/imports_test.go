@@ -0,0 +1,151 @@
+package imports
+
+import (
+	"testing"
+
+	"github.com/dark-shade/go-setup/pkg/vfs"
+)
+
+func TestRewrite(t *testing.T) {
+	fn := Chain(
+		Prefix("example.com/app/util", "example.com/app/internal/util"),
+		Prefix("example.com/app", "example.com/new"),
+	)
+
+	tests := []struct {
+		name string
+		src  string
+		// want is empty when no import changes
+		want string
+	}{
+		{
+			name: "single import",
+			src:  "package main\n\nimport \"example.com/app/util\"\n",
+			want: "package main\n\nimport \"example.com/app/internal/util\"\n",
+		},
+		{
+			name: "first matching func applied",
+			src:  "package main\n\nimport (\n\t\"example.com/app/pkg/api\"\n\t\"example.com/app/util/strings\"\n)\n",
+			want: "package main\n\nimport (\n\t\"example.com/app/internal/util/strings\"\n\t\"example.com/new/pkg/api\"\n)\n",
+		},
+		{
+			name: "named import and comments kept",
+			src:  "//go:build linux\n\npackage main\n\nimport (\n\t\"fmt\"\n\n\tu \"example.com/app/util\" // helpers\n)\n\nfunc main() { fmt.Println(u.Name) }\n",
+			want: "//go:build linux\n\npackage main\n\nimport (\n\t\"fmt\"\n\n\tu \"example.com/app/internal/util\" // helpers\n)\n\nfunc main() { fmt.Println(u.Name) }\n",
+		},
+		{
+			name: "prefix matches whole elements",
+			src:  "package main\n\nimport \"example.com/application\"\n",
+		},
+		{
+			name: "unchanged",
+			src:  "package main\n\nimport \"fmt\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Rewrite([]byte(tt.src), fn)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.want == "" {
+				if got != nil {
+					t.Errorf("got\n%s\nwant no change", got)
+				}
+				return
+			}
+			if string(got) != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRewriteInvalid(t *testing.T) {
+	if _, err := Rewrite([]byte("package main\n\nimport (\n"), Prefix("a", "b")); err == nil {
+		t.Error("got no error for an invalid source")
+	}
+}
+
+func TestRewriteFS(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		// want are the rewritten files, nil when the rewrite fails
+		want []string
+	}{
+		{
+			name: "rewritten",
+			files: map[string]string{
+				"main.go":             "package main\n\nimport \"example.com/app/util\"\n",
+				"util/util.go":        "package util\n",
+				"util/util_test.go":   "package util_test\n\nimport \"example.com/app/util\"\n",
+				"vendor/x/x.go":       "package x\n\nimport \"example.com/app/util\"\n",
+				"testdata/main.go":    "package main\n\nimport \"example.com/app/util\"\n",
+				"docs/notes.txt":      "import \"example.com/app/util\"\n",
+				"internal/x/x.go":     "package x\n\nimport \"example.com/app/util\"\n",
+				".git/hooks/hook.go":  "package hook\n\nimport \"example.com/app/util\"\n",
+				"internal/x/x_win.go": "//go:build windows\n\npackage x\n\nimport \"example.com/app/util\"\n",
+				".go-setup/base/m.go": "package main\n\nimport \"example.com/app/util\"\n",
+				"tools/go.mod":        "module example.com/app/tools\n",
+				"tools/tools.go":      "package tools\n\nimport \"example.com/app/util\"\n",
+			},
+			want: []string{"internal/x/x.go", "internal/x/x_win.go", "main.go", "util/util_test.go"},
+		},
+		{
+			name: "nothing written when a file cannot be parsed",
+			files: map[string]string{
+				"main.go":   "package main\n\nimport \"example.com/app/util\"\n",
+				"broken.go": "package main\n\nimport (\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := vfs.Memory()
+			for name, src := range tt.files {
+				if err := vfs.WriteFile(fsys, name, []byte(src), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := RewriteFS(fsys, Prefix("example.com/app/util", "example.com/app/internal/util"))
+			if tt.want == nil {
+				if err == nil {
+					t.Fatalf("got rewritten files %v, want an error", got)
+				}
+			} else {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(got) != len(tt.want) {
+					t.Fatalf("got rewritten files %v, want %v", got, tt.want)
+				}
+				for i := range tt.want {
+					if got[i] != tt.want[i] {
+						t.Errorf("got rewritten files %v, want %v", got, tt.want)
+						break
+					}
+				}
+			}
+
+			// the files which are not rewritten are left as they are
+			rewritten := map[string]bool{}
+			for _, name := range tt.want {
+				rewritten[name] = true
+			}
+			for name, src := range tt.files {
+				data, err := vfs.ReadFile(fsys, name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if changed := string(data) != src; changed != rewritten[name] {
+					t.Errorf("%s: got changed %v, want %v", name, changed, rewritten[name])
+				}
+			}
+		})
+	}
+}
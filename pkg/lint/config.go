@@ -0,0 +1,31 @@
+package lint
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/dark-shade/go-setup/pkg/vfs"
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultConfigPath is the rules file of a project used when none is given.
+var DefaultConfigPath = filepath.Join(".go-setup", "lint.yaml")
+
+// LoadConfig reads and validates the rules file.
+func LoadConfig(fsys vfs.FS, name string) (*Config, error) {
+	raw, err := vfs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{}
+	if err := yaml.UnmarshalStrict(raw, cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,125 @@
+package lint
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/dark-shade/go-setup/pkg/vfs"
+)
+
+// initSection is a section of the configuration of an init system and the
+// fields it sets.
+type initSection struct {
+	name   string
+	fields []string
+}
+
+// initFields are the sections every configuration of an init system has, by
+// init system, in the order of the configurations generated by go-setup. The
+// sections of supervisord are program sections, openrc scripts have none.
+var initFields = map[string][]initSection{
+	"systemd": {
+		{name: "Unit", fields: []string{"Description"}},
+		{name: "Service", fields: []string{"ExecStart", "User", "Restart"}},
+		{name: "Install", fields: []string{"WantedBy"}},
+	},
+	"supervisord": {
+		{name: "program", fields: []string{"command", "user", "autorestart"}},
+	},
+	"openrc": {
+		{name: "", fields: []string{"command", "command_user"}},
+	},
+}
+
+// checkInitConfigs reports the configurations of init/ missing a required field.
+func checkInitConfigs(p *Project) []Finding {
+	var findings []Finding
+	for _, f := range p.Files {
+		system := initSystem(f)
+		if system == "" {
+			continue
+		}
+
+		data, err := vfs.ReadFile(p.FS, filepath.FromSlash(f))
+		if err != nil {
+			findings = append(findings, Finding{Path: f, Message: err.Error()})
+			continue
+		}
+
+		if system == "openrc" && !bytes.HasPrefix(data, []byte("#!/sbin/openrc-run")) {
+			findings = append(findings, Finding{Path: f, Line: 1, Message: "openrc service script without #!/sbin/openrc-run"})
+		}
+
+		fields := initConfigFields(system, data)
+		for _, section := range initFields[system] {
+			for _, field := range section.fields {
+				if !fields[section.name][field] {
+					findings = append(findings, Finding{Path: f, Message: missingField(system, section.name, field)})
+				}
+			}
+		}
+	}
+	return findings
+}
+
+// initSystem returns the init system of the configuration file, empty when
+// the file is not one.
+func initSystem(name string) string {
+	switch {
+	case path.Dir(name) == "init/systemd" && strings.HasSuffix(name, ".service"):
+		return "systemd"
+	case path.Dir(name) == "init/supervisord" && strings.HasSuffix(name, ".conf"):
+		return "supervisord"
+	case path.Dir(name) == "init/openrc":
+		return "openrc"
+	}
+	return ""
+}
+
+// initConfigFields returns the fields set in each section of the
+// configuration. The sections of supervisord programs are all named program.
+func initConfigFields(system string, data []byte) map[string]map[string]bool {
+	fields := map[string]map[string]bool{}
+	section := ""
+
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		if system != "openrc" && strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.Trim(line, "[]")
+			if system == "supervisord" && strings.HasPrefix(section, "program:") {
+				section = "program"
+			}
+			continue
+		}
+
+		i := strings.Index(line, "=")
+		if i <= 0 {
+			continue
+		}
+		if fields[section] == nil {
+			fields[section] = map[string]bool{}
+		}
+		fields[section][strings.TrimSpace(line[:i])] = true
+	}
+	return fields
+}
+
+// missingField returns the message of a required field missing from a configuration.
+func missingField(system, section, field string) string {
+	switch system {
+	case "systemd":
+		return fmt.Sprintf("missing %s= in the [%s] section", field, section)
+	case "supervisord":
+		return fmt.Sprintf("missing %s= in the [program:...] section", field)
+	}
+	return fmt.Sprintf("missing %s= in the service script", field)
+}
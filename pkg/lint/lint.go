@@ -0,0 +1,174 @@
+// Package lint checks whether a project follows the golang-standards/project-layout
+// conventions go-setup generates.
+//
+// A project is loaded once, every enabled rule then inspects it and reports
+// findings with a severity. Rules can be disabled or given another severity
+// with a rules file.
+package lint
+
+import (
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/dark-shade/go-setup/pkg/vfs"
+)
+
+// Severity is how serious a finding is.
+type Severity string
+
+const (
+	// Off disables a rule in a rules file.
+	Off Severity = "off"
+	// Info is a suggestion.
+	Info Severity = "info"
+	// Warning is a deviation from the layout which should be fixed.
+	Warning Severity = "warning"
+	// Error is a deviation from the layout which breaks its guarantees.
+	Error Severity = "error"
+)
+
+// valid reports whether s is a known severity.
+func (s Severity) valid() bool {
+	switch s {
+	case Off, Info, Warning, Error:
+		return true
+	}
+	return false
+}
+
+// Finding is a violation of a rule.
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	// Path is the slash separated path of the file or directory, relative to the project root.
+	Path string `json:"path"`
+	// Line is the line of the violation in the file, 0 when it applies to the whole path.
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+// Rule is a check of the project layout.
+type Rule struct {
+	ID          string
+	Description string
+	// Severity is the severity of the findings when the rules file does not set it.
+	Severity Severity
+	check    func(p *Project) []Finding
+}
+
+// Rules returns the available rules sorted by id.
+func Rules() []Rule {
+	r := append([]Rule(nil), rules...)
+	sort.Slice(r, func(i, j int) bool { return r[i].ID < r[j].ID })
+	return r
+}
+
+// LookupRule returns the rule with the id.
+func LookupRule(id string) (Rule, bool) {
+	for _, r := range rules {
+		if r.ID == id {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
+
+// Run loads the project at the root of fsys and checks it with the rules
+// enabled by cfg, which may be nil. The findings are sorted by path, line and rule.
+func Run(fsys vfs.FS, cfg *Config) ([]Finding, error) {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+
+	p, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	for _, r := range Rules() {
+		severity := cfg.severity(r)
+		if severity == Off {
+			continue
+		}
+
+		for _, f := range r.check(p) {
+			if cfg.ignored(f.Path) {
+				continue
+			}
+			f.Rule, f.Severity = r.ID, severity
+			findings = append(findings, f)
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Rule < b.Rule
+	})
+
+	return findings, nil
+}
+
+// Count returns the number of findings of the severity.
+func Count(findings []Finding, severity Severity) int {
+	n := 0
+	for _, f := range findings {
+		if f.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+// Config is the content of a rules file.
+type Config struct {
+	// Rules maps rule ids to the severity of their findings, off disables the rule.
+	Rules map[string]Severity `yaml:"rules"`
+	// Ignore are path.Match patterns of paths whose findings are dropped.
+	Ignore []string `yaml:"ignore"`
+}
+
+// Validate checks that the config only refers to known rules and severities.
+func (c *Config) Validate() error {
+	for id, s := range c.Rules {
+		if _, ok := LookupRule(id); !ok {
+			return fmt.Errorf("unknown rule: %s", id)
+		}
+		if !s.valid() {
+			return fmt.Errorf("rule %s: invalid severity %q, expected off, info, warning or error", id, s)
+		}
+	}
+
+	for _, pattern := range c.Ignore {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("ignore pattern %q: %w", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// severity returns the severity of the findings of the rule.
+func (c *Config) severity(r Rule) Severity {
+	if s, ok := c.Rules[r.ID]; ok {
+		return s
+	}
+	return r.Severity
+}
+
+// ignored reports whether the findings of the path are dropped.
+func (c *Config) ignored(name string) bool {
+	for _, pattern := range c.Ignore {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,187 @@
+package lint
+
+import (
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dark-shade/go-setup/pkg/gomod"
+	"github.com/dark-shade/go-setup/pkg/vfs"
+)
+
+// skippedDirs are never loaded.
+var skippedDirs = map[string]bool{".git": true, ".go-setup": true, "vendor": true, "testdata": true, "node_modules": true}
+
+// Project is a loaded project.
+type Project struct {
+	FS vfs.FS
+	// Module is the module path of go.mod, empty when the project has none.
+	Module string
+	// Dirs are the slash separated directories of the project, "." for the root.
+	Dirs []string
+	// Files are the slash separated regular files of the project.
+	Files []string
+	// Packages are the go packages of the project by directory.
+	Packages map[string]*Package
+}
+
+// Package is the parsed go files of a directory.
+type Package struct {
+	Dir string
+	// Name is the package name of the non test files.
+	Name  string
+	Files []*File
+}
+
+// File is a parsed go file.
+type File struct {
+	Path    string
+	Package string
+	Test    bool
+	Imports []Import
+	// HasMain reports whether the file declares func main.
+	HasMain bool
+	// Exported reports whether the file declares an exported top level identifier.
+	Exported bool
+}
+
+// Import is an import of a file.
+type Import struct {
+	Path string
+	Line int
+}
+
+// ImportPath returns the import path of the package in dir.
+func (p *Project) ImportPath(dir string) string {
+	if dir == "." {
+		return p.Module
+	}
+	return p.Module + "/" + dir
+}
+
+// Load walks and parses the project at the root of fsys.
+func Load(fsys vfs.FS) (*Project, error) {
+	p := &Project{FS: fsys, Packages: map[string]*Package{}}
+
+	mod, err := gomod.Read(fsys, ".")
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if mod != nil {
+		p.Module = mod.Module
+	}
+
+	err = vfs.Walk(fsys, ".", func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel := filepath.ToSlash(name)
+		if info.IsDir() {
+			if rel != "." && (skippedDirs[info.Name()] || strings.HasPrefix(info.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			p.Dirs = append(p.Dirs, rel)
+			return nil
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		p.Files = append(p.Files, rel)
+
+		if strings.HasSuffix(rel, ".go") {
+			return p.parse(rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(p.Dirs)
+	sort.Strings(p.Files)
+	return p, nil
+}
+
+// parse parses the go file and adds it to the package of its directory.
+func (p *Project) parse(name string) error {
+	src, err := vfs.ReadFile(p.FS, filepath.FromSlash(name))
+	if err != nil {
+		return err
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, name, src, parser.SkipObjectResolution)
+	if err != nil {
+		// unparsable files are reported by the go tool, not by the layout rules
+		return nil
+	}
+
+	file := &File{
+		Path:    name,
+		Package: f.Name.Name,
+		Test:    strings.HasSuffix(name, "_test.go"),
+	}
+
+	for _, imp := range f.Imports {
+		ip, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		file.Imports = append(file.Imports, Import{Path: ip, Line: fset.Position(imp.Pos()).Line})
+	}
+
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Recv == nil && decl.Name.Name == "main" {
+				file.HasMain = true
+			}
+			if decl.Recv == nil && decl.Name.IsExported() {
+				file.Exported = true
+			}
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					file.Exported = file.Exported || spec.Name.IsExported()
+				case *ast.ValueSpec:
+					for _, n := range spec.Names {
+						file.Exported = file.Exported || n.IsExported()
+					}
+				}
+			}
+		}
+	}
+
+	dir := path.Dir(name)
+	pkg, ok := p.Packages[dir]
+	if !ok {
+		pkg = &Package{Dir: dir}
+		p.Packages[dir] = pkg
+	}
+	pkg.Files = append(pkg.Files, file)
+	if !file.Test || pkg.Name == "" {
+		pkg.Name = strings.TrimSuffix(file.Package, "_test")
+	}
+
+	return nil
+}
+
+// SortedPackages returns the packages sorted by directory.
+func (p *Project) SortedPackages() []*Package {
+	pkgs := make([]*Package, 0, len(p.Packages))
+	for _, pkg := range p.Packages {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Slice(pkgs, func(i, j int) bool { return pkgs[i].Dir < pkgs[j].Dir })
+	return pkgs
+}
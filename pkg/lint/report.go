@@ -0,0 +1,126 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Formats are the output formats of the findings.
+var Formats = []string{"text", "json", "sarif"}
+
+// Write writes the findings to w in the format.
+func Write(w io.Writer, format string, findings []Finding) error {
+	switch format {
+	case "text":
+		return WriteText(w, findings)
+	case "json":
+		return WriteJSON(w, findings)
+	case "sarif":
+		return WriteSARIF(w, findings)
+	}
+	return fmt.Errorf("invalid format: %s, expected one of %s", format, strings.Join(Formats, ", "))
+}
+
+// WriteText writes a line per finding, path:line: severity: message (rule).
+func WriteText(w io.Writer, findings []Finding) error {
+	for _, f := range findings {
+		location := f.Path
+		if f.Line > 0 {
+			location += fmt.Sprintf(":%d", f.Line)
+		}
+
+		if _, err := fmt.Fprintf(w, "%s: %s: %s (%s)\n", location, f.Severity, f.Message, f.Rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteJSON writes the findings as a JSON array.
+func WriteJSON(w io.Writer, findings []Finding) error {
+	if findings == nil {
+		findings = []Finding{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(findings)
+}
+
+// sarifLevels maps the severities to the levels of SARIF.
+var sarifLevels = map[Severity]string{Info: "note", Warning: "warning", Error: "error"}
+
+// WriteSARIF writes the findings as a SARIF 2.1.0 log, e.g. for GitHub code scanning.
+func WriteSARIF(w io.Writer, findings []Finding) error {
+	type message struct {
+		Text string `json:"text"`
+	}
+	type region struct {
+		StartLine int `json:"startLine"`
+	}
+	type artifactLocation struct {
+		URI string `json:"uri"`
+	}
+	type physicalLocation struct {
+		ArtifactLocation artifactLocation `json:"artifactLocation"`
+		Region           *region          `json:"region,omitempty"`
+	}
+	type location struct {
+		PhysicalLocation physicalLocation `json:"physicalLocation"`
+	}
+	type result struct {
+		RuleID    string     `json:"ruleId"`
+		Level     string     `json:"level"`
+		Message   message    `json:"message"`
+		Locations []location `json:"locations"`
+	}
+	type configuration struct {
+		Level string `json:"level"`
+	}
+	type rule struct {
+		ID                   string        `json:"id"`
+		ShortDescription     message       `json:"shortDescription"`
+		DefaultConfiguration configuration `json:"defaultConfiguration"`
+	}
+	type driver struct {
+		Name           string `json:"name"`
+		InformationURI string `json:"informationUri"`
+		Rules          []rule `json:"rules"`
+	}
+	type tool struct {
+		Driver driver `json:"driver"`
+	}
+	type run struct {
+		Tool    tool     `json:"tool"`
+		Results []result `json:"results"`
+	}
+	type log struct {
+		Schema  string `json:"$schema"`
+		Version string `json:"version"`
+		Runs    []run  `json:"runs"`
+	}
+
+	d := driver{Name: "go-setup", InformationURI: "https://github.com/dark-shade/go-setup"}
+	for _, r := range Rules() {
+		d.Rules = append(d.Rules, rule{ID: r.ID, ShortDescription: message{r.Description}, DefaultConfiguration: configuration{sarifLevels[r.Severity]}})
+	}
+
+	results := []result{}
+	for _, f := range findings {
+		loc := location{PhysicalLocation: physicalLocation{ArtifactLocation: artifactLocation{URI: f.Path}}}
+		if f.Line > 0 {
+			loc.PhysicalLocation.Region = &region{StartLine: f.Line}
+		}
+		results = append(results, result{RuleID: f.Rule, Level: sarifLevels[f.Severity], Message: message{f.Message}, Locations: []location{loc}})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []run{{Tool: tool{Driver: d}, Results: results}},
+	})
+}
@@ -0,0 +1,191 @@
+package lint
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// rules are the available rules.
+var rules = []Rule{
+	{
+		ID:          "root-go-files",
+		Description: "a command in the root only has main.go, its code belongs in internal/ or pkg/",
+		Severity:    Warning,
+		check:       checkRootGoFiles,
+	},
+	{
+		ID:          "no-src-dir",
+		Description: "src/ directories are a Java convention, go code is not put in a src/ directory",
+		Severity:    Error,
+		check:       checkSrcDirs,
+	},
+	{
+		ID:          "internal-import",
+		Description: "internal packages are only imported from the tree rooted at the parent of their internal/ directory",
+		Severity:    Error,
+		check:       checkInternalImports,
+	},
+	{
+		ID:          "cmd-main",
+		Description: "every directory of cmd/ is the main package of a binary",
+		Severity:    Error,
+		check:       checkCmdMain,
+	},
+	{
+		ID:          "license",
+		Description: "the project has a LICENSE file",
+		Severity:    Warning,
+		check:       checkLicense,
+	},
+	{
+		ID:          "pkg-private",
+		Description: "pkg/ is for code meant to be imported by other projects, private code belongs in internal/",
+		Severity:    Info,
+		check:       checkPkgPrivate,
+	},
+	{
+		ID:          "init-config",
+		Description: "the systemd, supervisord and openrc configurations of init/ set the command, user and restart policy",
+		Severity:    Error,
+		check:       checkInitConfigs,
+	},
+}
+
+// licenseFiles are the accepted names of the license file.
+var licenseFiles = []string{"LICENSE", "LICENSE.md", "LICENSE.txt", "COPYING"}
+
+// checkRootGoFiles reports the go files next to the main.go of a main package in the root.
+func checkRootGoFiles(p *Project) []Finding {
+	pkg, ok := p.Packages["."]
+	if !ok || pkg.Name != "main" {
+		return nil
+	}
+
+	var findings []Finding
+	for _, f := range pkg.Files {
+		if f.Path != "main.go" && !f.Test {
+			findings = append(findings, Finding{Path: f.Path, Message: "go file in the root besides main.go, move it to internal/ or pkg/"})
+		}
+	}
+	return findings
+}
+
+// checkSrcDirs reports the src directories.
+func checkSrcDirs(p *Project) []Finding {
+	var findings []Finding
+	for _, dir := range p.Dirs {
+		if path.Base(dir) == "src" {
+			findings = append(findings, Finding{Path: dir, Message: "src/ directory, put the code in cmd/, internal/ or pkg/ instead"})
+		}
+	}
+	return findings
+}
+
+// checkInternalImports reports the imports of internal packages of the module
+// the go tool would reject.
+func checkInternalImports(p *Project) []Finding {
+	if p.Module == "" {
+		return nil
+	}
+
+	var findings []Finding
+	for _, pkg := range p.SortedPackages() {
+		importer := p.ImportPath(pkg.Dir)
+
+		for _, f := range pkg.Files {
+			for _, imp := range f.Imports {
+				if imp.Path != p.Module && !strings.HasPrefix(imp.Path, p.Module+"/") {
+					continue
+				}
+
+				if CanImport(importer, imp.Path) {
+					continue
+				}
+
+				parent, _ := internalParent(imp.Path)
+				findings = append(findings, Finding{
+					Path:    f.Path,
+					Line:    imp.Line,
+					Message: fmt.Sprintf("import of %s from outside of %s", imp.Path, parent),
+				})
+			}
+		}
+	}
+	return findings
+}
+
+// CanImport reports whether the package with the import path importer can
+// import the package importPath under the visibility rules of internal packages.
+func CanImport(importer, importPath string) bool {
+	parent, ok := internalParent(importPath)
+	return !ok || importer == parent || strings.HasPrefix(importer, parent+"/")
+}
+
+// internalParent returns the import path of the parent of the last internal
+// element of the import path, and whether it has one.
+func internalParent(importPath string) (string, bool) {
+	elems := strings.Split(importPath, "/")
+	for i := len(elems) - 1; i > 0; i-- {
+		if elems[i] == "internal" {
+			return strings.Join(elems[:i], "/"), true
+		}
+	}
+	return "", false
+}
+
+// checkCmdMain reports the directories of cmd/ whose go files are not a main package with func main.
+func checkCmdMain(p *Project) []Finding {
+	var findings []Finding
+	for _, pkg := range p.SortedPackages() {
+		if path.Dir(pkg.Dir) != "cmd" {
+			continue
+		}
+
+		hasMain := false
+		for _, f := range pkg.Files {
+			hasMain = hasMain || (f.HasMain && !f.Test)
+		}
+
+		switch {
+		case pkg.Name != "main":
+			findings = append(findings, Finding{Path: pkg.Dir, Message: fmt.Sprintf("package %s in cmd/, binaries are main packages and libraries belong in internal/ or pkg/", pkg.Name)})
+		case !hasMain:
+			findings = append(findings, Finding{Path: pkg.Dir, Message: "main package without func main"})
+		}
+	}
+	return findings
+}
+
+// checkLicense reports a missing license file.
+func checkLicense(p *Project) []Finding {
+	for _, name := range licenseFiles {
+		for _, f := range p.Files {
+			if f == name {
+				return nil
+			}
+		}
+	}
+	return []Finding{{Path: "LICENSE", Message: "missing LICENSE file"}}
+}
+
+// checkPkgPrivate reports the packages of pkg/ without exported identifiers,
+// they cannot be used by other projects.
+func checkPkgPrivate(p *Project) []Finding {
+	var findings []Finding
+	for _, pkg := range p.SortedPackages() {
+		if pkg.Dir != "pkg" && !strings.HasPrefix(pkg.Dir, "pkg/") {
+			continue
+		}
+
+		exported := false
+		for _, f := range pkg.Files {
+			exported = exported || (f.Exported && !f.Test)
+		}
+
+		if !exported && pkg.Name != "main" {
+			findings = append(findings, Finding{Path: pkg.Dir, Message: "package without exported identifiers in pkg/, move it to internal/"})
+		}
+	}
+	return findings
+}
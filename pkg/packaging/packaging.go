@@ -0,0 +1,290 @@
+// Package packaging generates the release configuration of projects: a
+// goreleaser configuration building the binaries, archives, packages,
+// checksums and changelog of a release, and a nfpm configuration packaging the
+// linux binaries and their systemd units as deb and rpm packages locally.
+package packaging
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dark-shade/go-setup/pkg/project"
+)
+
+// Tools are the supported release tools.
+var Tools = []string{"goreleaser"}
+
+// Paths of the generated files.
+const (
+	nfpmConfig    = "build/package/nfpm.yaml"
+	postinstall   = "build/package/scripts/postinstall.sh"
+	preremove     = "build/package/scripts/preremove.sh"
+	goreleaserCfg = ".goreleaser.yaml"
+)
+
+// Platform is a GOOS/GOARCH pair.
+type Platform struct {
+	OS   string
+	Arch string
+}
+
+// Config are the project settings the release depends on.
+type Config struct {
+	// Name is the name of the project, e.g. orders.
+	Name   string
+	Module string
+	// Binaries are the main packages released.
+	Binaries []project.Binary
+	// VersionVar is the variable the version is set to with -ldflags -X, e.g. main.version.
+	VersionVar string
+	// Platforms are the platforms the binaries are built for.
+	Platforms []Platform
+	// CGO builds the binaries with cgo, for the platform of the build only.
+	CGO bool
+	// Files are the files added to the archives, e.g. LICENSE.
+	Files []string
+	// Maintainer and License of the packages.
+	Maintainer string
+	License    string
+}
+
+// Render returns the goreleaser and nfpm configurations, and the scripts of
+// the packages creating the users of the systemd units.
+func Render(cfg Config) []project.File {
+	files := []project.File{
+		{Path: goreleaserCfg, Data: goreleaser(cfg)},
+		{Path: nfpmConfig, Data: nfpm(cfg)},
+	}
+	if len(units(cfg)) > 0 {
+		files = append(files,
+			project.File{Path: postinstall, Data: postinstallScript(cfg), Executable: true},
+			project.File{Path: preremove, Data: preremoveScript(cfg), Executable: true},
+		)
+	}
+	return files
+}
+
+// goreleaser returns the goreleaser configuration.
+func goreleaser(cfg Config) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# goreleaser configuration, see https://goreleaser.com\nversion: 2\n\nproject_name: %s\n\n", cfg.Name)
+	b.WriteString("before:\n  hooks:\n    - go mod tidy\n\nbuilds:\n")
+
+	goos, goarch, ignore := platforms(cfg.Platforms)
+	for _, bin := range cfg.Binaries {
+		fmt.Fprintf(&b, "  - id: %s\n    main: %s\n    binary: %s\n", bin.Name, bin.Package, bin.Name)
+		if cfg.CGO {
+			b.WriteString("    env:\n      - CGO_ENABLED=1\n    goos: [linux]\n    goarch: [amd64]\n")
+		} else {
+			fmt.Fprintf(&b, "    env:\n      - CGO_ENABLED=0\n    goos: [%s]\n    goarch: [%s]\n", strings.Join(goos, ", "), strings.Join(goarch, ", "))
+			if len(ignore) > 0 {
+				b.WriteString("    ignore:\n")
+				for _, p := range ignore {
+					fmt.Fprintf(&b, "      - goos: %s\n        goarch: %s\n", p.OS, p.Arch)
+				}
+			}
+		}
+		b.WriteString("    flags:\n      - -trimpath\n    ldflags:\n      - -s -w")
+		if cfg.VersionVar != "" {
+			fmt.Fprintf(&b, " -X %s={{ .Version }}", cfg.VersionVar)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(`
+archives:
+  - formats: [tar.gz]
+    name_template: '{{ .ProjectName }}_{{ .Version }}_{{ .Os }}_{{ .Arch }}'
+    format_overrides:
+      - goos: windows
+        formats: [zip]
+`)
+	if len(cfg.Files) > 0 {
+		b.WriteString("    files:\n")
+		for _, f := range cfg.Files {
+			fmt.Fprintf(&b, "      - %s\n", f)
+		}
+	}
+
+	var ids []string
+	for _, bin := range cfg.Binaries {
+		ids = append(ids, bin.Name)
+	}
+	fmt.Fprintf(&b, `
+# the linux binaries are packaged as the package target of the task runner
+# does with build/package/nfpm.yaml
+nfpms:
+  - id: packages
+    package_name: %s
+    ids: [%s]
+    formats: [deb, rpm]
+    bindir: /opt/%s/bin
+    file_name_template: '{{ .ConventionalFileName }}'
+`, cfg.Name, strings.Join(ids, ", "), cfg.Name)
+	packageInfo(&b, cfg, "    ")
+	if u := units(cfg); len(u) > 0 {
+		b.WriteString("    contents:\n")
+		unitContents(&b, u, "      ")
+		fmt.Fprintf(&b, "    scripts:\n      postinstall: %s\n      preremove: %s\n", postinstall, preremove)
+	}
+
+	b.WriteString(`
+checksum:
+  name_template: checksums.txt
+
+snapshot:
+  version_template: '{{ incpatch .Version }}-next'
+
+# the commits are grouped by their conventional commit type
+changelog:
+  use: git
+  sort: asc
+  groups:
+    - title: Features
+      regexp: '^.*?feat(\(.+\))??!?:.+$'
+      order: 0
+    - title: Bug fixes
+      regexp: '^.*?fix(\(.+\))??!?:.+$'
+      order: 1
+    - title: Others
+      order: 999
+  filters:
+    exclude:
+      - '^docs(\(.+\))?:'
+      - '^test(\(.+\))?:'
+      - '^chore(\(.+\))?:'
+`)
+	return []byte(b.String())
+}
+
+// platforms returns the operating systems and architectures of the
+// platforms, and the combinations of them that are not platforms.
+func platforms(list []Platform) (goos, goarch []string, ignore []Platform) {
+	has := map[Platform]bool{}
+	for _, p := range list {
+		has[p] = true
+		if !contains(goos, p.OS) {
+			goos = append(goos, p.OS)
+		}
+		if !contains(goarch, p.Arch) {
+			goarch = append(goarch, p.Arch)
+		}
+	}
+
+	for _, os := range goos {
+		for _, arch := range goarch {
+			if p := (Platform{OS: os, Arch: arch}); !has[p] {
+				ignore = append(ignore, p)
+			}
+		}
+	}
+	return goos, goarch, ignore
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+// nfpm returns the nfpm configuration of the packages of the binaries built
+// to bin/. The binaries are installed in /opt/<name>/bin, where the systemd
+// units run them from.
+func nfpm(cfg Config) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, `# nfpm configuration of the deb and rpm packages, see https://nfpm.goreleaser.com
+# VERSION and GOARCH are read from the environment.
+name: %s
+arch: ${GOARCH}
+platform: linux
+version: ${VERSION}
+version_schema: none
+`, cfg.Name)
+	packageInfo(&b, cfg, "")
+
+	b.WriteString("\ncontents:\n")
+	for _, bin := range cfg.Binaries {
+		fmt.Fprintf(&b, "  - src: bin/%s\n    dst: /opt/%s/bin/%s\n    file_info:\n      mode: 0755\n", bin.Name, cfg.Name, bin.Name)
+	}
+	unitContents(&b, units(cfg), "  ")
+
+	if len(units(cfg)) > 0 {
+		fmt.Fprintf(&b, "\nscripts:\n  postinstall: %s\n  preremove: %s\n", postinstall, preremove)
+	}
+	return []byte(b.String())
+}
+
+// packageInfo writes the maintainer, description, homepage and license of the packages at the indent.
+func packageInfo(b *strings.Builder, cfg Config, indent string) {
+	fmt.Fprintf(b, "%smaintainer: %s\n%sdescription: %s\n", indent, yamlQuote(maintainer(cfg)), indent, yamlQuote(cfg.Name+" of "+cfg.Module))
+	if strings.HasPrefix(cfg.Module, "github.com/") {
+		fmt.Fprintf(b, "%shomepage: https://%s\n", indent, cfg.Module)
+	}
+	if cfg.License != "" {
+		fmt.Fprintf(b, "%slicense: %s\n", indent, cfg.License)
+	}
+}
+
+// unitContents writes the package contents installing the systemd units at the indent.
+func unitContents(b *strings.Builder, units []string, indent string) {
+	for _, u := range units {
+		fmt.Fprintf(b, "%s- src: init/systemd/%s.service\n%s  dst: /lib/systemd/system/%s.service\n", indent, u, indent, u)
+	}
+}
+
+// units returns the names of the binaries run as systemd units.
+func units(cfg Config) []string {
+	var names []string
+	for _, bin := range cfg.Binaries {
+		if bin.Unit {
+			names = append(names, bin.Name)
+		}
+	}
+	return names
+}
+
+// maintainer returns the maintainer of the packages.
+func maintainer(cfg Config) string {
+	if cfg.Maintainer == "" {
+		return "Unknown"
+	}
+	return cfg.Maintainer
+}
+
+// postinstallScript creates the users of the units and reloads systemd.
+func postinstallScript(cfg Config) []byte {
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\nset -e\n\n# the units run as a system user named after their binary\n")
+	for _, u := range units(cfg) {
+		fmt.Fprintf(&b, "id -u %s >/dev/null 2>&1 || useradd --system --no-create-home --shell /usr/sbin/nologin %s\n", u, u)
+	}
+	b.WriteString("\nif command -v systemctl >/dev/null 2>&1; then\n\tsystemctl daemon-reload\nfi\n")
+	return []byte(b.String())
+}
+
+// preremoveScript stops the units before their binaries are removed.
+func preremoveScript(cfg Config) []byte {
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\nset -e\n\nif command -v systemctl >/dev/null 2>&1; then\n")
+	for _, u := range units(cfg) {
+		fmt.Fprintf(&b, "\tsystemctl stop %s.service || true\n\tsystemctl disable %s.service || true\n", u, u)
+	}
+	b.WriteString("fi\n")
+	return []byte(b.String())
+}
+
+// yamlQuote returns s as a single quoted YAML string.
+func yamlQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// licenseNames are the SPDX identifiers of the licenses of go-setup.
+var licenseNames = map[string]string{"mit": "MIT", "apache": "Apache-2.0"}
+
+// License returns the SPDX identifier of a license of go-setup, empty for none.
+func License(name string) string {
+	return licenseNames[name]
+}
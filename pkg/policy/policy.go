@@ -0,0 +1,308 @@
+// Package policy checks projects against the rules of an organization: the
+// paths every project has or must not have, the allowed licenses, the targets
+// of the Makefile and the settings of go.mod.
+//
+// A policy is a YAML file:
+//
+//	required:
+//	  - CODEOWNERS
+//	  - SECURITY.md
+//	forbidden:
+//	  - src
+//	  - "*.exe"
+//	licenses: [mit, apache]
+//	makefile:
+//	  targets: [test, lint]
+//	gomod:
+//	  module: github.com/acme/*
+//	  go: "1.17"
+package policy
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dark-shade/go-setup/pkg/gomod"
+	"github.com/dark-shade/go-setup/pkg/vfs"
+	"gopkg.in/yaml.v2"
+)
+
+// Policy is the content of a policy file.
+type Policy struct {
+	// Required are path.Match patterns, each matching at least one path of the project.
+	Required []string `yaml:"required"`
+	// Forbidden are path.Match patterns no path of the project matches. A
+	// pattern without slash matches the base name of the paths.
+	Forbidden []string `yaml:"forbidden"`
+	// Licenses are the allowed licenses, e.g. mit or apache. Empty allows any license.
+	Licenses []string `yaml:"licenses"`
+	Makefile Makefile `yaml:"makefile"`
+	GoMod    GoMod    `yaml:"gomod"`
+}
+
+// Makefile are the requirements of the Makefile.
+type Makefile struct {
+	// Targets are the targets the Makefile defines.
+	Targets []string `yaml:"targets"`
+}
+
+// GoMod are the requirements of go.mod.
+type GoMod struct {
+	// Module is a path.Match pattern of the module path.
+	Module string `yaml:"module"`
+	// Go is the minimum go version of the go directive, e.g. 1.17.
+	Go string `yaml:"go"`
+}
+
+// Violation is a requirement of the policy the project does not meet.
+type Violation struct {
+	// Rule is the section of the policy, e.g. required or makefile.
+	Rule    string
+	Message string
+}
+
+// String returns the violation as rule: message.
+func (v Violation) String() string {
+	return v.Rule + ": " + v.Message
+}
+
+// Load reads and validates the policy file.
+func Load(fsys vfs.FS, name string) (*Policy, error) {
+	raw, err := vfs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Policy{}
+	if err := yaml.UnmarshalStrict(raw, p); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return p, nil
+}
+
+// Validate checks the patterns and versions of the policy.
+func (p *Policy) Validate() error {
+	patterns := append(append([]string{p.GoMod.Module}, p.Required...), p.Forbidden...)
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("pattern %q: %w", pattern, err)
+		}
+	}
+
+	if p.GoMod.Go != "" {
+		if _, ok := parseGoVersion(p.GoMod.Go); !ok {
+			return fmt.Errorf("gomod: invalid go version %q, expected e.g. 1.17", p.GoMod.Go)
+		}
+	}
+
+	for _, l := range p.Licenses {
+		if _, ok := licenseMarkers[l]; !ok {
+			return fmt.Errorf("unknown license: %s, expected one of %s", l, strings.Join(KnownLicenses(), ", "))
+		}
+	}
+
+	return nil
+}
+
+// licenseMarkers are texts identifying the licenses in a LICENSE file.
+var licenseMarkers = map[string]string{
+	"mit":          "Permission is hereby granted, free of charge",
+	"apache":       "Apache License",
+	"bsd-3-clause": "Neither the name of the copyright holder nor the names of its",
+	"mpl-2.0":      "Mozilla Public License Version 2.0",
+	"gpl-3.0":      "GNU GENERAL PUBLIC LICENSE",
+}
+
+// KnownLicenses returns the licenses a policy can allow.
+func KnownLicenses() []string {
+	var names []string
+	for name := range licenseMarkers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Check returns the violations of the policy by the project at the root of fsys.
+func (p *Policy) Check(fsys vfs.FS) ([]Violation, error) {
+	paths, err := walk(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []Violation
+
+	for _, pattern := range p.Required {
+		if len(match(paths, pattern)) == 0 {
+			violations = append(violations, Violation{Rule: "required", Message: "missing " + pattern})
+		}
+	}
+
+	for _, pattern := range p.Forbidden {
+		for _, m := range match(paths, pattern) {
+			violations = append(violations, Violation{Rule: "forbidden", Message: m + " matches " + pattern})
+		}
+	}
+
+	checks := []func(vfs.FS) ([]Violation, error){p.checkLicense, p.checkMakefile, p.checkGoMod}
+	for _, check := range checks {
+		v, err := check(fsys)
+		if err != nil {
+			return nil, err
+		}
+		violations = append(violations, v...)
+	}
+
+	return violations, nil
+}
+
+// walk returns the slash separated paths of the files and directories of fsys.
+func walk(fsys vfs.FS) ([]string, error) {
+	var paths []string
+	err := vfs.Walk(fsys, ".", func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && info.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if name != "." {
+			paths = append(paths, filepath.ToSlash(name))
+		}
+		return nil
+	})
+	return paths, err
+}
+
+// match returns the paths matching the pattern, patterns without slash match base names.
+func match(paths []string, pattern string) []string {
+	var matched []string
+	for _, p := range paths {
+		name := p
+		if !strings.Contains(pattern, "/") {
+			name = path.Base(p)
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			matched = append(matched, p)
+		}
+	}
+	return matched
+}
+
+// checkLicense checks the LICENSE file is one of the allowed licenses.
+func (p *Policy) checkLicense(fsys vfs.FS) ([]Violation, error) {
+	if len(p.Licenses) == 0 {
+		return nil, nil
+	}
+
+	data, err := vfs.ReadFile(fsys, "LICENSE")
+	if os.IsNotExist(err) {
+		return []Violation{{Rule: "licenses", Message: "missing LICENSE, allowed licenses are " + strings.Join(p.Licenses, ", ")}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, l := range p.Licenses {
+		if bytes.Contains(data, []byte(licenseMarkers[l])) {
+			return nil, nil
+		}
+	}
+	return []Violation{{Rule: "licenses", Message: "LICENSE is none of " + strings.Join(p.Licenses, ", ")}}, nil
+}
+
+// makeTarget matches the rule lines of a Makefile, the targets are before the colon.
+var makeTarget = regexp.MustCompile(`^([^\s:#=][^:#=]*):([^=]|$)`)
+
+// checkMakefile checks the Makefile defines the required targets.
+func (p *Policy) checkMakefile(fsys vfs.FS) ([]Violation, error) {
+	if len(p.Makefile.Targets) == 0 {
+		return nil, nil
+	}
+
+	data, err := vfs.ReadFile(fsys, "Makefile")
+	if os.IsNotExist(err) {
+		return []Violation{{Rule: "makefile", Message: "missing Makefile with targets " + strings.Join(p.Makefile.Targets, ", ")}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	defined := map[string]bool{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if m := makeTarget.FindStringSubmatch(scanner.Text()); m != nil {
+			for _, t := range strings.Fields(m[1]) {
+				defined[t] = true
+			}
+		}
+	}
+
+	var violations []Violation
+	for _, t := range p.Makefile.Targets {
+		if !defined[t] {
+			violations = append(violations, Violation{Rule: "makefile", Message: "missing target " + t})
+		}
+	}
+	return violations, nil
+}
+
+// checkGoMod checks the module path and go version of go.mod.
+func (p *Policy) checkGoMod(fsys vfs.FS) ([]Violation, error) {
+	if p.GoMod.Module == "" && p.GoMod.Go == "" {
+		return nil, nil
+	}
+
+	mod, err := gomod.Read(fsys, ".")
+	if os.IsNotExist(err) {
+		return []Violation{{Rule: "gomod", Message: "missing go.mod"}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []Violation
+	if p.GoMod.Module != "" {
+		if ok, _ := path.Match(p.GoMod.Module, mod.Module); !ok {
+			violations = append(violations, Violation{Rule: "gomod", Message: fmt.Sprintf("module %s does not match %s", mod.Module, p.GoMod.Module)})
+		}
+	}
+
+	if p.GoMod.Go != "" {
+		min, _ := parseGoVersion(p.GoMod.Go)
+		v, ok := parseGoVersion(mod.Go)
+		if !ok || v[0] < min[0] || (v[0] == min[0] && v[1] < min[1]) {
+			violations = append(violations, Violation{Rule: "gomod", Message: fmt.Sprintf("go version %q is older than %s", mod.Go, p.GoMod.Go)})
+		}
+	}
+
+	return violations, nil
+}
+
+// parseGoVersion returns the major and minor numbers of a go version, e.g. 1.17 or 1.21.3.
+func parseGoVersion(v string) ([2]int, bool) {
+	parts := strings.Split(v, ".")
+	if len(parts) < 2 {
+		return [2]int{}, false
+	}
+
+	major, err1 := strconv.Atoi(parts[0])
+	minor, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil {
+		return [2]int{}, false
+	}
+	return [2]int{major, minor}, true
+}
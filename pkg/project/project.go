@@ -0,0 +1,92 @@
+// Package project holds what the generators and refactorings of go-setup share
+// about the projects they work on: the files generated for them, their
+// binaries, and the names derived from their module path and packages.
+package project
+
+import (
+	"go/ast"
+	"go/token"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// File is a generated file.
+type File struct {
+	// Path is the slash separated path of the file in the project.
+	Path string
+	Data []byte
+	// Executable is set for the scripts.
+	Executable bool
+}
+
+// Binary is a main package of the project.
+type Binary struct {
+	// Name is the name of the binary, e.g. orders.
+	Name string
+	// Package is the relative package path of the main package, e.g. ./cmd/orders.
+	Package string
+	// Unit is set when the binary runs as the systemd unit init/systemd/<name>.service.
+	Unit bool
+}
+
+// majorVersion matches the major version suffix of module paths.
+var majorVersion = regexp.MustCompile(`^v[0-9]+$`)
+
+// Name returns the name of the project of the module, its last path element
+// without major version suffix.
+func Name(module string) string {
+	elems := strings.Split(module, "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && majorVersion.MatchString(name) {
+		name = elems[len(elems)-2]
+	}
+	return name
+}
+
+// PackageName returns name lower cased and stripped of every character not
+// allowed in a package name.
+func PackageName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+
+	pkg := b.String()
+	if pkg == "" || unicode.IsDigit(rune(pkg[0])) {
+		pkg = "pkg" + pkg
+	}
+	return pkg
+}
+
+// HasPackages reports whether dir or one of its subdirectories has go files,
+// files being the slash separated files of the project.
+func HasPackages(files []string, dir string) bool {
+	for _, f := range files {
+		if strings.HasSuffix(f, ".go") && strings.HasPrefix(f, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// DeclaresVar reports whether f declares the package level variable name.
+func DeclaresVar(f *ast.File, name string) bool {
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+
+		for _, spec := range gen.Specs {
+			for _, ident := range spec.(*ast.ValueSpec).Names {
+				if ident.Name == name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,306 @@
+// Package refactor moves and renames the packages of a module, rewriting the
+// package clauses and importers with go/ast and go/format.
+package refactor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dark-shade/go-setup/pkg/imports"
+	"github.com/dark-shade/go-setup/pkg/lint"
+	"github.com/dark-shade/go-setup/pkg/project"
+	"github.com/dark-shade/go-setup/pkg/vfs"
+)
+
+// MoveResult is the outcome of a package move.
+type MoveResult struct {
+	// From and To are the slash separated directories of the package.
+	From string
+	To   string
+	// OldName and NewName are the package names, equal when the name is kept.
+	OldName string
+	NewName string
+	// Rewritten are the go files whose imports, package clause or references were rewritten.
+	Rewritten []string
+}
+
+// Move moves the package in the directory from, and the packages below it,
+// to the directory to of the project at the root of fsys. The package clause
+// is renamed after the new directory, and the importers of the module are
+// rewritten. Moves breaking the visibility rules of internal packages, and
+// files which cannot be parsed, are refused before anything is changed. A
+// move failing halfway is undone.
+func Move(fsys vfs.FS, from, to string) (*MoveResult, error) {
+	from, to = cleanDir(from), cleanDir(to)
+	if from == "." || to == "." || strings.HasPrefix(from, "../") || strings.HasPrefix(to, "../") {
+		return nil, errors.New("both directories are below the project root")
+	}
+	if from == to || strings.HasPrefix(to, from+"/") {
+		return nil, fmt.Errorf("cannot move %s into itself", from)
+	}
+
+	p, err := lint.Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	if p.Module == "" {
+		return nil, errors.New("go.mod not found in the project root")
+	}
+
+	pkg, ok := p.Packages[from]
+	if !ok {
+		// a directory grouping packages is moved without renaming
+		pkg = &lint.Package{Dir: from}
+		if !project.HasPackages(p.Files, from) {
+			return nil, fmt.Errorf("%s has no go package", from)
+		}
+	}
+
+	exists, err := vfs.Exists(fsys, filepath.FromSlash(to))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if exists {
+		return nil, fmt.Errorf("%s already exists", to)
+	}
+
+	if err := checkVisibility(p, from, to); err != nil {
+		return nil, err
+	}
+
+	res := &MoveResult{From: from, To: to, OldName: pkg.Name, NewName: pkg.Name}
+	if pkg.Name != "" && pkg.Name != "main" && path.Base(from) != path.Base(to) {
+		res.NewName = project.PackageName(path.Base(to))
+	}
+
+	// every file is rewritten in memory first, so a file which cannot be
+	// parsed fails the move before anything changes
+	oldPath, newPath := p.ImportPath(from), p.ImportPath(to)
+	changes, err := imports.Changes(fsys, imports.Prefix(oldPath, newPath))
+	if err != nil {
+		return nil, err
+	}
+	if res.NewName != res.OldName {
+		if changes, err = renamePackage(fsys, changes, from, newPath, res.OldName, res.NewName); err != nil {
+			return nil, err
+		}
+	}
+
+	j := vfs.NewJournal(fsys)
+	if err := res.apply(j, changes); err != nil {
+		if undoErr := j.Undo(); undoErr != nil {
+			return nil, fmt.Errorf("%w, undoing the move failed: %v", err, undoErr)
+		}
+		return nil, err
+	}
+	return res, nil
+}
+
+// apply moves the directory, then writes the rewritten go files at their new path.
+func (r *MoveResult) apply(j *vfs.Journal, changes []imports.Change) error {
+	if err := j.MkdirAll(path.Dir(r.To)); err != nil {
+		return err
+	}
+	if err := j.Rename(r.From, r.To); err != nil {
+		return err
+	}
+
+	for _, c := range changes {
+		name := moved(c.Path, r.From, r.To)
+		if err := j.WriteFile(name, c.Data, c.Mode); err != nil {
+			return err
+		}
+		r.Rewritten = append(r.Rewritten, name)
+	}
+	sort.Strings(r.Rewritten)
+	return nil
+}
+
+// cleanDir returns the directory as a clean slash separated path.
+func cleanDir(dir string) string {
+	return path.Clean(filepath.ToSlash(dir))
+}
+
+// moved returns the new location of the slash separated path after moving from to to.
+func moved(p, from, to string) string {
+	if p == from {
+		return to
+	}
+	if strings.HasPrefix(p, from+"/") {
+		return to + strings.TrimPrefix(p, from)
+	}
+	return p
+}
+
+// checkVisibility returns an error listing the imports of the module allowed
+// today which the move would make invalid.
+func checkVisibility(p *lint.Project, from, to string) error {
+	var broken []string
+
+	for _, pkg := range p.SortedPackages() {
+		importer := p.ImportPath(pkg.Dir)
+		newImporter := p.ImportPath(moved(pkg.Dir, from, to))
+
+		for _, f := range pkg.Files {
+			for _, imp := range f.Imports {
+				if imp.Path != p.Module && !strings.HasPrefix(imp.Path, p.Module+"/") {
+					continue
+				}
+
+				dir := strings.TrimPrefix(strings.TrimPrefix(imp.Path, p.Module), "/")
+				if dir == "" {
+					dir = "."
+				}
+				newImport := p.ImportPath(moved(dir, from, to))
+
+				if lint.CanImport(importer, imp.Path) && !lint.CanImport(newImporter, newImport) {
+					broken = append(broken, fmt.Sprintf("%s:%d imports %s", moved(f.Path, from, to), imp.Line, newImport))
+				}
+			}
+		}
+	}
+
+	if len(broken) > 0 {
+		return fmt.Errorf("moving %s to %s breaks the visibility of internal packages:\n  %s", from, to, strings.Join(broken, "\n  "))
+	}
+	return nil
+}
+
+// renamePackage returns the changes with the package clause of the files of
+// dir renamed from oldName to newName, and the references to the package of
+// its importers which do not name the import. The importers are read from
+// changes when their imports are rewritten, they import importPath there.
+func renamePackage(fsys vfs.FS, changes []imports.Change, dir, importPath, oldName, newName string) ([]imports.Change, error) {
+	byPath := map[string]int{}
+	for i, c := range changes {
+		byPath[c.Path] = i
+	}
+
+	err := vfs.Walk(fsys, ".", func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			skip, err := imports.SkipDir(fsys, name)
+			if err != nil {
+				return err
+			}
+			if skip {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(name, ".go") || !info.Mode().IsRegular() {
+			return nil
+		}
+
+		slashed := filepath.ToSlash(name)
+		i, changed := byPath[slashed]
+		var src []byte
+		if changed {
+			src = changes[i].Data
+		} else if src, err = vfs.ReadFile(fsys, name); err != nil {
+			return err
+		}
+
+		updated, err := renameInFile(src, path.Dir(slashed) == dir, importPath, oldName, newName)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		switch {
+		case updated == nil:
+		case changed:
+			changes[i].Data = updated
+		default:
+			byPath[slashed] = len(changes)
+			changes = append(changes, imports.Change{Path: slashed, Data: updated, Mode: info.Mode().Perm()})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// renameInFile renames the package clause of src when inDir, and the
+// references to the package when src imports it without a name. updated is
+// nil when nothing changes.
+func renameInFile(src []byte, inDir bool, importPath, oldName, newName string) (updated []byte, err error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	// identifiers to rename with their new name, replaced as text at their offsets
+	renames := map[*ast.Ident]string{}
+
+	if inDir {
+		switch f.Name.Name {
+		case oldName:
+			renames[f.Name] = newName
+		case oldName + "_test":
+			// external test package
+			renames[f.Name] = newName + "_test"
+		}
+	}
+
+	imported := false
+	for _, imp := range f.Imports {
+		if p, err := strconv.Unquote(imp.Path.Value); err == nil && p == importPath && imp.Name == nil {
+			imported = true
+		}
+	}
+
+	if imported {
+		// unresolved identifiers selecting from the old name refer to the package
+		ast.Inspect(f, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok {
+				if x, ok := sel.X.(*ast.Ident); ok && x.Name == oldName && x.Obj == nil {
+					renames[x] = newName
+				}
+			}
+			return true
+		})
+	}
+
+	if len(renames) == 0 {
+		return nil, nil
+	}
+	return replaceIdents(fset, src, renames)
+}
+
+// replaceIdents replaces the identifiers of src with their new names and formats the result.
+func replaceIdents(fset *token.FileSet, src []byte, renames map[*ast.Ident]string) ([]byte, error) {
+	idents := make([]*ast.Ident, 0, len(renames))
+	for ident := range renames {
+		idents = append(idents, ident)
+	}
+	sort.Slice(idents, func(i, j int) bool { return idents[i].Pos() < idents[j].Pos() })
+
+	var buf bytes.Buffer
+	last := 0
+	for _, ident := range idents {
+		start := fset.Position(ident.Pos()).Offset
+		buf.Write(src[last:start])
+		buf.WriteString(renames[ident])
+		last = start + len(ident.Name)
+	}
+	buf.Write(src[last:])
+
+	return format.Source(buf.Bytes())
+}
@@ -0,0 +1,124 @@
+package refactor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dark-shade/go-setup/pkg/vfs"
+)
+
+func TestMove(t *testing.T) {
+	files := map[string]string{
+		"go.mod":                   "module example.com/app\n\ngo 1.17\n",
+		"main.go":                  "package main\n\nimport \"example.com/app/internal/foo\"\n\nfunc main() { foo.F() }\n",
+		"internal/foo/foo.go":      "package foo\n\n// F does nothing.\nfunc F() {}\n",
+		"internal/foo/foo_test.go": "package foo_test\n\nimport (\n\t\"testing\"\n\n\t\"example.com/app/internal/foo\"\n)\n\nfunc TestF(t *testing.T) { foo.F() }\n",
+		"internal/foo/x/x.go":      "package x\n",
+		".go-setup/base/main.go":   "package main\n\nimport \"example.com/app/internal/foo\"\n\nfunc main() { foo.F() }\n",
+		"tools/go.mod":             "module example.com/app/tools\n\ngo 1.17\n",
+		"tools/foo.go":             "package foo\n\nfunc F() {}\n",
+		"tools/main.go":            "package main\n\nimport \"example.com/app/internal/foo\"\n\nfunc main() { foo.F() }\n",
+	}
+
+	tests := []struct {
+		name      string
+		from, to  string
+		broken    string
+		want      map[string]string
+		rewritten []string
+		err       bool
+	}{
+		{
+			name: "moved and renamed",
+			from: "internal/foo",
+			to:   "pkg/bar",
+			want: map[string]string{
+				"main.go":                "package main\n\nimport \"example.com/app/pkg/bar\"\n\nfunc main() { bar.F() }\n",
+				"pkg/bar/foo.go":         "package bar\n\n// F does nothing.\nfunc F() {}\n",
+				"pkg/bar/foo_test.go":    "package bar_test\n\nimport (\n\t\"testing\"\n\n\t\"example.com/app/pkg/bar\"\n)\n\nfunc TestF(t *testing.T) { bar.F() }\n",
+				"pkg/bar/x/x.go":         "package x\n",
+				".go-setup/base/main.go": files[".go-setup/base/main.go"],
+				"tools/foo.go":           files["tools/foo.go"],
+				"tools/main.go":          files["tools/main.go"],
+			},
+			rewritten: []string{"main.go", "pkg/bar/foo.go", "pkg/bar/foo_test.go"},
+		},
+		{
+			name: "moved keeping the name",
+			from: "internal/foo",
+			to:   "pkg/foo",
+			want: map[string]string{
+				"main.go":        "package main\n\nimport \"example.com/app/pkg/foo\"\n\nfunc main() { foo.F() }\n",
+				"pkg/foo/foo.go": files["internal/foo/foo.go"],
+			},
+			rewritten: []string{"main.go", "pkg/foo/foo_test.go"},
+		},
+		{
+			name:   "nothing changed when a file cannot be parsed",
+			from:   "internal/foo",
+			to:     "pkg/bar",
+			broken: "internal/foo/broken.go",
+			err:    true,
+		},
+		{name: "existing destination", from: "internal/foo", to: "tools", err: true},
+		{name: "into itself", from: "internal/foo", to: "internal/foo/y", err: true},
+		{name: "no package", from: "docs", to: "pkg/docs", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the in-memory filesystem does not move the content of renamed directories
+			fsys := vfs.BasePath(vfs.OS(), t.TempDir())
+			for name, src := range files {
+				if err := vfs.WriteFile(fsys, name, []byte(src), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.broken != "" {
+				if err := vfs.WriteFile(fsys, tt.broken, []byte("package foo\n\nfunc (\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			res, err := Move(fsys, tt.from, tt.to)
+			if tt.err {
+				if err == nil {
+					t.Fatalf("got %+v, want an error", res)
+				}
+				// the project is left as it was
+				for name, src := range files {
+					data, err := vfs.ReadFile(fsys, name)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if string(data) != src {
+						t.Errorf("%s: got\n%s\nwant it unchanged", name, data)
+					}
+				}
+				if exists, _ := vfs.Exists(fsys, tt.to); exists && tt.to != "tools" {
+					t.Errorf("%s was created", tt.to)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(res.Rewritten, tt.rewritten) {
+				t.Errorf("got rewritten files %v, want %v", res.Rewritten, tt.rewritten)
+			}
+			for name, want := range tt.want {
+				data, err := vfs.ReadFile(fsys, name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(data) != want {
+					t.Errorf("%s: got\n%s\nwant\n%s", name, data, want)
+				}
+			}
+			if exists, _ := vfs.Exists(fsys, tt.from); exists {
+				t.Errorf("%s is still there", tt.from)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+// Package release bumps the version of projects keeping a VERSION file and a
+// Keep a Changelog CHANGELOG.md, see https://keepachangelog.com.
+//
+// A release bumps the semantic version of the VERSION file, moves the
+// unreleased changes of the changelog to a section of the new version and
+// optionally commits both files and tags the commit.
+package release
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/dark-shade/go-setup/pkg/changelog"
+	"github.com/dark-shade/go-setup/pkg/gomod"
+	"github.com/dark-shade/go-setup/pkg/semver"
+	"github.com/dark-shade/go-setup/pkg/vfs"
+)
+
+// Files of the project read and written by a release.
+const (
+	VersionFile   = "VERSION"
+	ChangelogFile = changelog.File
+)
+
+// Options are the inputs of a release.
+type Options struct {
+	// Part is the part of the version to bump, one of semver.Parts.
+	Part string
+	// PreID is the identifier of new prereleases, semver.DefaultPreID when empty.
+	PreID string
+	// Date is the release date of the changelog section, today when zero.
+	Date time.Time
+}
+
+// Result is the outcome of a Bump.
+type Result struct {
+	Previous semver.Version
+	Version  semver.Version
+	// Changelog reports whether the changelog was updated, false when the project has none.
+	Changelog bool
+}
+
+// Files returns the files written by the release.
+func (r *Result) Files() []string {
+	if r.Changelog {
+		return []string{VersionFile, ChangelogFile}
+	}
+	return []string{VersionFile}
+}
+
+// Bump bumps the version of the VERSION file of the project at the root of
+// fsys, and releases the unreleased changes of its CHANGELOG.md, if any. New
+// compare links of the changelog point to the repository of GitHub modules.
+func Bump(fsys vfs.FS, opts Options) (*Result, error) {
+	data, err := vfs.ReadFile(fsys, VersionFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.New("the project has no VERSION file")
+		}
+		return nil, err
+	}
+
+	prev, err := semver.Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("VERSION: %w", err)
+	}
+	next, err := prev.Bump(opts.Part, opts.PreID)
+	if err != nil {
+		return nil, err
+	}
+	res := &Result{Previous: prev, Version: next}
+
+	// the changelog is written first, as it is the one refusing a release
+	current, err := vfs.ReadFile(fsys, ChangelogFile)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		date := opts.Date
+		if date.IsZero() {
+			date = time.Now()
+		}
+
+		version := next
+		version.Prefix, version.Build = "", ""
+
+		released, err := changelog.Cut(current, version.String(), next.Tag(), date.Format(changelog.DateLayout), RepoURL(fsys))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ChangelogFile, err)
+		}
+		if err := vfs.WriteFile(fsys, ChangelogFile, released, 0644); err != nil {
+			return nil, err
+		}
+		res.Changelog = true
+	}
+
+	if err := vfs.WriteFile(fsys, VersionFile, []byte(next.String()+"\n"), 0644); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// RepoURL returns the URL of the repository of the GitHub module at the root
+// of fsys, empty for the other modules.
+func RepoURL(fsys vfs.FS) string {
+	mod, err := gomod.Read(fsys, ".")
+	if err != nil || mod == nil {
+		return ""
+	}
+
+	elems := strings.Split(mod.Module, "/")
+	if len(elems) < 3 || elems[0] != "github.com" {
+		return ""
+	}
+	return "https://" + strings.Join(elems[:3], "/")
+}
+
+// CheckTag verifies that the version can be committed and tagged in the git
+// repository at dir: git is installed, the tracked files have no uncommitted
+// changes and the tag of the version does not exist yet. It is called before
+// Bump writes anything, so a release is never left half done.
+func CheckTag(dir string, version semver.Version) error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return errors.New("git is needed to tag the release: " + err.Error())
+	}
+
+	status, err := git(dir, "status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		return err
+	}
+	if status != "" {
+		return errors.New("the working tree has uncommitted changes, commit or stash them before tagging the release")
+	}
+
+	exists, err := tagExists(dir, version.Tag())
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("tag %s already exists", version.Tag())
+	}
+	return nil
+}
+
+// tagExists reports whether the git repository at dir has the tag.
+func tagExists(dir, tag string) (bool, error) {
+	cmd := exec.Command("git", "rev-parse", "--quiet", "--verify", "refs/tags/"+tag)
+	cmd.Dir = dir
+
+	err := cmd.Run()
+	var exit *exec.ExitError
+	if errors.As(err, &exit) && exit.ExitCode() == 1 {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("git rev-parse: %w", err)
+	}
+	return true, nil
+}
+
+// Tag commits the files of the release in the git repository at dir and
+// creates the annotated tag of the version on the commit.
+func Tag(dir string, res *Result) error {
+	msg := "Release " + res.Version.Tag()
+	for _, args := range [][]string{
+		append([]string{"add", "--"}, res.Files()...),
+		append([]string{"commit", "-m", msg, "--"}, res.Files()...),
+		{"tag", "-a", res.Version.Tag(), "-m", msg},
+	} {
+		if _, err := git(dir, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// git runs git with args in dir and returns its trimmed output.
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,343 @@
+// Package rename changes the module path of a project: go.mod, the imports and
+// comments of every go file, the references of the generated files, and the
+// go-setup manifest.
+package rename
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dark-shade/go-setup/pkg/gomod"
+	"github.com/dark-shade/go-setup/pkg/imports"
+	"github.com/dark-shade/go-setup/pkg/scaffold"
+	"github.com/dark-shade/go-setup/pkg/vfs"
+)
+
+// textFiles are the files whose references to the module path are updated,
+// along with the files recorded in the manifest and the init system
+// configurations of initDir, e.g. the version set with -ldflags -X.
+var textFiles = []string{
+	"Makefile", "Dockerfile", "README.md", "Taskfile.yml", "justfile",
+	".goreleaser.yaml", "build/package/nfpm.yaml",
+}
+
+// initDir holds the init system configurations, which describe the binaries
+// with the module path.
+const initDir = "init"
+
+// Result is the outcome of a module rename.
+type Result struct {
+	Old string
+	New string
+	// Rewritten are the go files whose imports or comments were rewritten.
+	Rewritten []string
+	// Updated are the other files referencing the module path which were updated.
+	Updated []string
+	// Literals are the string literals of go files containing the old module
+	// path, they are not rewritten since they are not necessarily imports.
+	Literals []Literal
+}
+
+// Literal is a string literal of a go file.
+type Literal struct {
+	Path  string
+	Line  int
+	Value string
+}
+
+// Module renames the module of the project at the root of fsys to module.
+// Every file is read and rewritten in memory first, so a go file which cannot
+// be parsed leaves the project untouched. Nested modules are left alone.
+// The recorded content of the manifest is updated the same way as the
+// files, so they do not drift.
+func Module(fsys vfs.FS, module string) (*Result, error) {
+	if module == "" || strings.ContainsAny(module, " \t\"'`\\") {
+		return nil, fmt.Errorf("invalid module path: %q", module)
+	}
+
+	data, err := vfs.ReadFile(fsys, "go.mod")
+	if err != nil {
+		return nil, err
+	}
+
+	mod, err := gomod.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	if mod.Module == module {
+		return nil, errors.New("the module path is already " + module)
+	}
+
+	res := &Result{Old: mod.Module, New: module}
+
+	goMod, err := gomod.SetModule(data, module)
+	if err != nil {
+		return nil, err
+	}
+
+	replace := referenceReplacer(res.Old, res.New)
+
+	changes, literals, err := goChanges(fsys, res.Old, res.New, replace)
+	if err != nil {
+		return nil, err
+	}
+	res.Literals = literals
+
+	m, err := scaffold.ReadManifest(fsys)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	names, err := referenceFiles(fsys, m)
+	if err != nil {
+		return nil, err
+	}
+
+	var updates []imports.Change
+	for _, name := range names {
+		data, err := vfs.ReadFile(fsys, filepath.FromSlash(name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		updated := replace(data)
+		if bytes.Equal(updated, data) {
+			continue
+		}
+
+		info, err := fsys.Stat(filepath.FromSlash(name))
+		if err != nil {
+			return nil, err
+		}
+		updates = append(updates, imports.Change{Path: name, Data: updated, Mode: info.Mode().Perm()})
+	}
+
+	// every change is known, the files are written
+	if err := vfs.WriteFile(fsys, "go.mod", goMod, 0644); err != nil {
+		return nil, err
+	}
+	res.Updated = append(res.Updated, "go.mod")
+
+	for _, c := range changes {
+		if err := vfs.WriteFile(fsys, filepath.FromSlash(c.Path), c.Data, c.Mode); err != nil {
+			return nil, err
+		}
+		res.Rewritten = append(res.Rewritten, c.Path)
+	}
+
+	for _, u := range updates {
+		if err := vfs.WriteFile(fsys, filepath.FromSlash(u.Path), u.Data, u.Mode); err != nil {
+			return nil, err
+		}
+		res.Updated = append(res.Updated, u.Path)
+	}
+
+	if m != nil {
+		if err := renameManifest(fsys, m, res, replace); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// referenceReplacer returns a function replacing the references to the old
+// module path, and the paths below it, with the new module path.
+func referenceReplacer(old, new string) func([]byte) []byte {
+	re := regexp.MustCompile(`(^|[^A-Za-z0-9._~-])` + regexp.QuoteMeta(old) + `($|[^A-Za-z0-9_~-])`)
+
+	return func(data []byte) []byte {
+		// the boundaries are consumed, adjacent references need another pass
+		for {
+			updated := re.ReplaceAll(data, []byte("${1}"+new+"${2}"))
+			if bytes.Equal(updated, data) {
+				return data
+			}
+			data = updated
+		}
+	}
+}
+
+// referenceFiles returns the slash separated paths of the files other than
+// go files whose references to the module path are updated.
+func referenceFiles(fsys vfs.FS, m *scaffold.Manifest) ([]string, error) {
+	seen := map[string]bool{}
+	var names []string
+	add := func(name string) {
+		if !seen[name] && !strings.HasSuffix(name, ".go") {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	for _, name := range textFiles {
+		add(name)
+	}
+	if m != nil {
+		for _, f := range m.Files {
+			add(f.Path)
+		}
+	}
+
+	err := vfs.Walk(fsys, initDir, func(name string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) && name == initDir {
+			return filepath.SkipDir
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			add(filepath.ToSlash(name))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// renameManifest updates the module path of the options and base content
+// recorded by go-setup init.
+func renameManifest(fsys vfs.FS, m *scaffold.Manifest, res *Result, replace func([]byte) []byte) error {
+	m.Options.ModulePath = res.New
+	err := scaffold.UpdateBase(fsys, m, func(name string, data []byte) []byte {
+		if !strings.HasSuffix(name, ".go") {
+			return replace(data)
+		}
+		// the base content of go files is rewritten as the files are
+		updated, _, err := rewriteGo(data, res.Old, res.New, replace)
+		if err != nil || updated == nil {
+			return data
+		}
+		return updated
+	})
+	if err != nil {
+		return err
+	}
+
+	res.Updated = append(res.Updated, filepath.ToSlash(filepath.Join(scaffold.ManifestDir, "manifest.yaml")))
+	return nil
+}
+
+// goChanges returns the changes rewriting the imports and comments of every
+// go file of fsys from the old module path to the new one, and the string
+// literals still containing the old module path. The directories of
+// imports.SkipDir are skipped.
+func goChanges(fsys vfs.FS, old, new string, replace func([]byte) []byte) ([]imports.Change, []Literal, error) {
+	var changes []imports.Change
+	var found []Literal
+
+	err := vfs.Walk(fsys, ".", func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			skip, err := imports.SkipDir(fsys, name)
+			if err != nil {
+				return err
+			}
+			if skip {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(name, ".go") || !info.Mode().IsRegular() {
+			return nil
+		}
+
+		src, err := vfs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+
+		updated, literals, err := rewriteGo(src, old, new, replace)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		for _, l := range literals {
+			l.Path = filepath.ToSlash(name)
+			found = append(found, l)
+		}
+		if updated != nil {
+			changes = append(changes, imports.Change{Path: filepath.ToSlash(name), Data: updated, Mode: info.Mode().Perm()})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return changes, found, nil
+}
+
+// rewriteGo rewrites the imports and the references of the comments of the go
+// source src from the old module path to the new one. It returns the string
+// literals containing the old module path, other than import paths, without
+// their path. updated is nil when nothing changes.
+func rewriteGo(src []byte, old, new string, replace func([]byte) []byte) (updated []byte, found []Literal, err error) {
+	data, err := imports.Rewrite(src, imports.Prefix(old, new))
+	if err != nil {
+		return nil, nil, err
+	}
+	if data == nil {
+		data = src
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", data, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.ImportSpec:
+			return false
+		case *ast.BasicLit:
+			if n.Kind != token.STRING {
+				return true
+			}
+			if v, err := strconv.Unquote(n.Value); err == nil && strings.Contains(v, old) {
+				found = append(found, Literal{Line: fset.Position(n.Pos()).Line, Value: n.Value})
+			}
+		}
+		return true
+	})
+
+	// the comments are replaced as text at their offsets
+	var buf bytes.Buffer
+	last := 0
+	for _, g := range f.Comments {
+		for _, c := range g.List {
+			start := fset.Position(c.Pos()).Offset
+			buf.Write(data[last:start])
+			buf.Write(replace([]byte(c.Text)))
+			last = start + len(c.Text)
+		}
+	}
+	buf.Write(data[last:])
+
+	if bytes.Equal(buf.Bytes(), src) {
+		return nil, found, nil
+	}
+	updated, err = format.Source(buf.Bytes())
+	return updated, found, err
+}
@@ -0,0 +1,181 @@
+package rename
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dark-shade/go-setup/pkg/vfs"
+)
+
+// project are the files of a project of github.com/jane/orders.
+var project = map[string]string{
+	"go.mod": "module github.com/jane/orders\n\ngo 1.17\n",
+	"cmd/orders/main.go": `package main
+
+import (
+	"fmt"
+
+	"github.com/jane/orders/internal/version"
+)
+
+// main prints the version of github.com/jane/orders.
+func main() {
+	fmt.Println(version.Version, "https://github.com/jane/orders/issues")
+}
+`,
+	"internal/version/version.go": `// Package version reports the version of github.com/jane/orders.
+package version
+
+// Version is set with -ldflags "-X github.com/jane/orders/internal/version.Version=v1.0.0".
+var Version = "dev"
+`,
+	"Taskfile.yml":                "    cmds:\n      - go build -ldflags \"-X github.com/jane/orders/internal/version.Version={{.VERSION}}\" ./cmd/orders\n",
+	"justfile":                    "build:\n    go build -ldflags \"-X github.com/jane/orders/internal/version.Version=dev\" ./cmd/orders\n",
+	".goreleaser.yaml":            "    ldflags:\n      - -s -w -X github.com/jane/orders/internal/version.Version={{ .Version }}\n",
+	"build/package/nfpm.yaml":     "description: 'orders of github.com/jane/orders'\nhomepage: https://github.com/jane/orders\n",
+	"init/systemd/orders.service": "[Unit]\nDescription=orders of github.com/jane/orders\n",
+	"docs/install.md":             "go install github.com/jane/orders/cmd/orders@latest\n",
+	"docs/notes.txt":              "see github.com/jane/orders-legacy\n",
+	"tools/go.mod":                "module github.com/jane/orders/tools\n\ngo 1.17\n",
+	"tools/tools.go":              "// Package tools of github.com/jane/orders.\npackage tools\n\nimport _ \"github.com/jane/orders/internal/version\"\n",
+	".go-setup/manifest.yaml": `version: 1
+options:
+  modulePath: github.com/jane/orders
+files:
+- path: docs/install.md
+  source: layout
+  sha256: ""
+- path: internal/version/version.go
+  source: layout
+  sha256: ""
+`,
+}
+
+func TestModule(t *testing.T) {
+	tests := []struct {
+		name   string
+		broken bool
+		// want are the files changed by the rename, nil when it fails
+		want      map[string]string
+		rewritten []string
+		updated   []string
+		literals  []Literal
+	}{
+		{
+			name: "renamed",
+			want: map[string]string{
+				"go.mod": "module github.com/acme/orders\n\ngo 1.17\n",
+				"cmd/orders/main.go": `package main
+
+import (
+	"fmt"
+
+	"github.com/acme/orders/internal/version"
+)
+
+// main prints the version of github.com/acme/orders.
+func main() {
+	fmt.Println(version.Version, "https://github.com/jane/orders/issues")
+}
+`,
+				"internal/version/version.go": `// Package version reports the version of github.com/acme/orders.
+package version
+
+// Version is set with -ldflags "-X github.com/acme/orders/internal/version.Version=v1.0.0".
+var Version = "dev"
+`,
+				"Taskfile.yml":                "    cmds:\n      - go build -ldflags \"-X github.com/acme/orders/internal/version.Version={{.VERSION}}\" ./cmd/orders\n",
+				"justfile":                    "build:\n    go build -ldflags \"-X github.com/acme/orders/internal/version.Version=dev\" ./cmd/orders\n",
+				".goreleaser.yaml":            "    ldflags:\n      - -s -w -X github.com/acme/orders/internal/version.Version={{ .Version }}\n",
+				"build/package/nfpm.yaml":     "description: 'orders of github.com/acme/orders'\nhomepage: https://github.com/acme/orders\n",
+				"init/systemd/orders.service": "[Unit]\nDescription=orders of github.com/acme/orders\n",
+				"docs/install.md":             "go install github.com/acme/orders/cmd/orders@latest\n",
+			},
+			rewritten: []string{"cmd/orders/main.go", "internal/version/version.go"},
+			updated: []string{
+				"go.mod", ".goreleaser.yaml", "Taskfile.yml", "build/package/nfpm.yaml", "docs/install.md",
+				"init/systemd/orders.service", "justfile", ".go-setup/manifest.yaml",
+			},
+			literals: []Literal{{Path: "cmd/orders/main.go", Line: 11, Value: `"https://github.com/jane/orders/issues"`}},
+		},
+		{name: "nothing changed when a file cannot be parsed", broken: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := vfs.Memory()
+			for name, data := range project {
+				if err := vfs.WriteFile(fsys, name, []byte(data), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			// the base content is the content of the files
+			for _, name := range []string{"docs/install.md", "internal/version/version.go"} {
+				if err := vfs.WriteFile(fsys, ".go-setup/base/"+name, []byte(project[name]), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.broken {
+				if err := vfs.WriteFile(fsys, "internal/broken.go", []byte("package internal\n\nfunc (\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			res, err := Module(fsys, "github.com/acme/orders")
+			if tt.want == nil {
+				if err == nil {
+					t.Fatalf("got %+v, want an error", res)
+				}
+			} else {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(res.Rewritten, tt.rewritten) {
+					t.Errorf("got rewritten %v, want %v", res.Rewritten, tt.rewritten)
+				}
+				if !reflect.DeepEqual(res.Updated, tt.updated) {
+					t.Errorf("got updated %v, want %v", res.Updated, tt.updated)
+				}
+				if !reflect.DeepEqual(res.Literals, tt.literals) {
+					t.Errorf("got literals %+v, want %+v", res.Literals, tt.literals)
+				}
+			}
+
+			for name, data := range project {
+				want, changed := tt.want[name]
+				if !changed {
+					want = data
+				}
+				got, err := vfs.ReadFile(fsys, name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if name == ".go-setup/manifest.yaml" {
+					if renamed := strings.Contains(string(got), "modulePath: github.com/acme/orders\n"); renamed != (tt.want != nil) {
+						t.Errorf("%s: got\n%s", name, got)
+					}
+					continue
+				}
+				if string(got) != want {
+					t.Errorf("%s: got\n%s\nwant\n%s", name, got, want)
+				}
+			}
+
+			// the base content follows the files, so status reports no change
+			for _, name := range []string{"docs/install.md", "internal/version/version.go"} {
+				base, err := vfs.ReadFile(fsys, ".go-setup/base/"+name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				data, err := vfs.ReadFile(fsys, name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(base) != string(data) {
+					t.Errorf("%s: got base\n%s\nwant\n%s", name, base, data)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package scaffold
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ArchiveFormats are the supported archive formats.
+var ArchiveFormats = []string{"tar.gz", "zip"}
+
+// archiveTime is the modification time of every archive entry, so archives are
+// reproducible. It is the earliest time representable in a zip file.
+var archiveTime = time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// ArchiveSink collects the entries of a plan and writes them as an archive
+// when closed. Entries are sorted and have deterministic timestamps and owners,
+// so the same plan always produces the same bytes.
+type ArchiveSink struct {
+	// Prefix is prepended to every path of the archive, e.g. the project name.
+	Prefix string
+
+	w       io.Writer
+	format  string
+	entries map[string]Entry
+}
+
+// NewArchiveSink returns a sink writing an archive of the given format to w.
+func NewArchiveSink(w io.Writer, format string) (*ArchiveSink, error) {
+	if format != "tar.gz" && format != "zip" {
+		return nil, fmt.Errorf("invalid archive format: %s. Valid values are %s", format, strings.Join(ArchiveFormats, " or "))
+	}
+
+	return &ArchiveSink{w: w, format: format, entries: make(map[string]Entry)}, nil
+}
+
+// Exists reports whether the path was already added to the archive.
+func (s *ArchiveSink) Exists(name string) (bool, error) {
+	_, ok := s.entries[path.Clean(name)]
+	return ok, nil
+}
+
+// MkdirAll adds a directory along with any necessary parents.
+func (s *ArchiveSink) MkdirAll(name string, perm fs.FileMode) error {
+	s.add(Entry{Kind: DirEntry, Path: name, Mode: perm})
+	return nil
+}
+
+// WriteFile adds a file with the given content.
+func (s *ArchiveSink) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	s.add(Entry{Kind: FileEntry, Path: name, Data: data, Mode: perm})
+	return nil
+}
+
+// Symlink adds name as a symbolic link to target.
+func (s *ArchiveSink) Symlink(target, name string) error {
+	s.add(Entry{Kind: SymlinkEntry, Path: name, Data: []byte(target), Mode: 0777})
+	return nil
+}
+
+// add records the entry and its missing parent directories.
+func (s *ArchiveSink) add(e Entry) {
+	e.Path = path.Clean(e.Path)
+	s.entries[e.Path] = e
+
+	for dir := path.Dir(e.Path); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if _, ok := s.entries[dir]; ok {
+			break
+		}
+		s.entries[dir] = Entry{Kind: DirEntry, Path: dir, Mode: dirMode}
+	}
+}
+
+// sorted returns the entries sorted by path.
+func (s *ArchiveSink) sorted() []Entry {
+	entries := make([]Entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	return entries
+}
+
+// name returns the archive name of the entry, directories end with a slash.
+func (s *ArchiveSink) name(e Entry) string {
+	name := path.Join(s.Prefix, e.Path)
+	if e.Kind == DirEntry {
+		name += "/"
+	}
+	return name
+}
+
+// Close writes the archive. It does not close the underlying writer.
+func (s *ArchiveSink) Close() error {
+	if s.format == "zip" {
+		return s.writeZip()
+	}
+	return s.writeTarGz()
+}
+
+func (s *ArchiveSink) writeTarGz() error {
+	gw := gzip.NewWriter(s.w)
+	tw := tar.NewWriter(gw)
+
+	for _, e := range s.sorted() {
+		hdr := &tar.Header{
+			Name:    s.name(e),
+			Mode:    int64(e.Mode.Perm()),
+			ModTime: archiveTime,
+			Format:  tar.FormatPAX,
+		}
+
+		switch e.Kind {
+		case DirEntry:
+			hdr.Typeflag = tar.TypeDir
+		case SymlinkEntry:
+			hdr.Typeflag = tar.TypeSymlink
+			hdr.Linkname = string(e.Data)
+		default:
+			hdr.Typeflag = tar.TypeReg
+			hdr.Size = int64(len(e.Data))
+		}
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+
+		if e.Kind == FileEntry {
+			if _, err := tw.Write(e.Data); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+func (s *ArchiveSink) writeZip() error {
+	zw := zip.NewWriter(s.w)
+
+	for _, e := range s.sorted() {
+		hdr := &zip.FileHeader{
+			Name:     s.name(e),
+			Method:   zip.Deflate,
+			Modified: archiveTime,
+		}
+
+		switch e.Kind {
+		case DirEntry:
+			hdr.Method = zip.Store
+			hdr.SetMode(fs.ModeDir | e.Mode.Perm())
+		case SymlinkEntry:
+			hdr.SetMode(fs.ModeSymlink | 0777)
+		default:
+			hdr.SetMode(e.Mode.Perm())
+		}
+
+		w, err := zw.CreateHeader(hdr)
+		if err != nil {
+			return err
+		}
+
+		if e.Kind != DirEntry {
+			if _, err := w.Write(e.Data); err != nil {
+				return err
+			}
+		}
+	}
+
+	return zw.Close()
+}
+
+// Tidy runs go mod tidy on a copy of the entries in a temporary directory and
+// adds the resulting go.mod and go.sum, so an archived project builds right
+// away like one written to disk. It needs the go toolchain in the PATH.
+func (s *ArchiveSink) Tidy() error {
+	dir, err := os.MkdirTemp("", "go-setup-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	for _, e := range s.sorted() {
+		name := filepath.Join(dir, filepath.FromSlash(e.Path))
+		switch e.Kind {
+		case DirEntry:
+			err = os.MkdirAll(name, dirMode)
+		case FileEntry:
+			err = os.WriteFile(name, e.Data, fileMode)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := Tidy(dir); err != nil {
+		return err
+	}
+
+	for _, name := range []string{"go.mod", "go.sum"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.WriteFile(name, data, fileMode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
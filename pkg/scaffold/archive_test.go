@@ -0,0 +1,123 @@
+package scaffold
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+)
+
+func TestArchiveSinkDeterministic(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+	}{
+		{"tar.gz", "tar.gz"},
+		{"zip", "zip"},
+	}
+
+	// the same entries added in different orders
+	write := func(t *testing.T, format string, reverse bool) []byte {
+		t.Helper()
+
+		var buf bytes.Buffer
+		sink, err := NewArchiveSink(&buf, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sink.Prefix = "app"
+
+		steps := []func() error{
+			func() error { return sink.WriteFile("go.mod", []byte("module app\n"), 0644) },
+			func() error { return sink.WriteFile("cmd/app/main.go", []byte("package main\n"), 0644) },
+			func() error { return sink.WriteFile("scripts/build.sh", []byte("#!/bin/sh\n"), 0755) },
+			func() error { return sink.MkdirAll("docs", 0755) },
+			func() error { return sink.Symlink("README.md", "docs/README.md") },
+		}
+		for i := range steps {
+			step := steps[i]
+			if reverse {
+				step = steps[len(steps)-1-i]
+			}
+			if err := step(); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if err := sink.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first := write(t, tt.format, false)
+			second := write(t, tt.format, true)
+
+			if !bytes.Equal(first, second) {
+				t.Errorf("archives differ, got %d and %d bytes", len(first), len(second))
+			}
+		})
+	}
+}
+
+func TestArchiveSinkEntries(t *testing.T) {
+	var buf bytes.Buffer
+	sink, err := NewArchiveSink(&buf, "tar.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink.Prefix = "app"
+
+	if err := sink.WriteFile("cmd/app/main.go", []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.WriteFile("go.mod", []byte("module app\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	gr, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gr)
+
+	want := []string{"app/cmd/", "app/cmd/app/", "app/cmd/app/main.go", "app/go.mod"}
+	var got []string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !hdr.ModTime.Equal(archiveTime) {
+			t.Errorf("%s: got modification time %v, want %v", hdr.Name, hdr.ModTime, archiveTime)
+		}
+		if hdr.Uid != 0 || hdr.Gid != 0 || hdr.Uname != "" || hdr.Gname != "" {
+			t.Errorf("%s: got owner %d:%d %s:%s, want none", hdr.Name, hdr.Uid, hdr.Gid, hdr.Uname, hdr.Gname)
+		}
+		got = append(got, hdr.Name)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("got entries %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entry %d: got %s, want %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestArchiveSinkFormat(t *testing.T) {
+	if _, err := NewArchiveSink(io.Discard, "rar"); err == nil {
+		t.Error("got no error for an invalid format")
+	}
+}
@@ -0,0 +1,53 @@
+package scaffold
+
+import (
+	"github.com/dark-shade/go-setup/pkg/ci"
+	"github.com/dark-shade/go-setup/pkg/task"
+)
+
+// validCI reports whether the CI provider is supported.
+func validCI(provider string) bool {
+	for _, p := range ci.Providers() {
+		if p == provider {
+			return true
+		}
+	}
+	return false
+}
+
+// ciSource produces the pipeline of the CI provider of the options.
+type ciSource struct{}
+
+// NewCISource returns the source of the CI pipeline, which invokes the targets of the generated task runner file.
+func NewCISource() Source {
+	return &ciSource{}
+}
+
+// Name returns the name of the source.
+func (s *ciSource) Name() string {
+	return "ci"
+}
+
+// Entries returns the configuration files of the CI provider, none when the options have no provider.
+func (s *ciSource) Entries(opts Options) ([]Entry, error) {
+	if opts.CI == "" {
+		return nil, nil
+	}
+
+	runner, _ := task.LookupRunner(opts.Runner)
+	files, err := ci.Render(opts.CI, ci.Config{
+		GoVersion:     GoVersion(),
+		Runner:        runner.Command,
+		InstallRunner: runner.Install,
+		Goreleaser:    opts.Release == "goreleaser",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(files))
+	for _, f := range files {
+		entries = append(entries, Entry{Kind: FileEntry, Path: f.Path, Data: f.Data, Mode: fileMode})
+	}
+	return entries, nil
+}
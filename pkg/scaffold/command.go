@@ -0,0 +1,266 @@
+package scaffold
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dark-shade/go-setup/pkg/project"
+	"github.com/dark-shade/go-setup/pkg/vfs"
+	"github.com/spf13/afero"
+)
+
+// CommandData is the data of a cobra command available to the command templates.
+type CommandData struct {
+	// Use is the name of the command, the last element of its path, e.g. set.
+	Use string
+	// Path is the space separated path of the command below the root, e.g. config set.
+	Path string
+	// Var is the name of the variable of the command, e.g. configSetCmd.
+	Var string
+	// Exported is the camel case path of the command, e.g. ConfigSet.
+	Exported string
+	// Func is the name of the function running the command, e.g. runConfigSet.
+	Func string
+	// Key is the viper key prefix of the flags of the command, e.g. config.set.
+	Key string
+	// File is the name of the command file without extension, e.g. config_set.
+	File string
+	// Args are the quoted arguments invoking the command, e.g. "config", "set".
+	Args string
+	// ParentVar is the name of the variable of the parent command, e.g. configCmd or rootCmd.
+	ParentVar string
+}
+
+// NewCommandData returns the template data of the command at cmdPath, a slash
+// separated path below the root command, e.g. config/set. When parent is
+// empty, the parent is the command path without its last element.
+func NewCommandData(cmdPath, parent string) (CommandData, error) {
+	segments := strings.Split(strings.Trim(cmdPath, "/"), "/")
+	for _, s := range segments {
+		if !validComponentName.MatchString(s) {
+			return CommandData{}, fmt.Errorf("invalid command path: %s. Each element starts with a letter followed by letters, digits, - or _", cmdPath)
+		}
+	}
+
+	if parent == "" {
+		parent = strings.Join(segments[:len(segments)-1], "/")
+	} else {
+		// the parent path is prepended so that args and names reflect the full path
+		parentSegments := strings.Split(strings.Trim(parent, "/"), "/")
+		if parentSegments[0] == "root" {
+			parentSegments = parentSegments[1:]
+		}
+		segments = append(parentSegments, segments[len(segments)-1])
+		parent = strings.Join(parentSegments, "/")
+	}
+
+	exported := ""
+	quoted := make([]string, 0, len(segments))
+	for _, s := range segments {
+		exported += exportedName(s)
+		quoted = append(quoted, strconv.Quote(s))
+	}
+
+	return CommandData{
+		Use:       segments[len(segments)-1],
+		Path:      strings.Join(segments, " "),
+		Var:       commandVar(strings.Join(segments, "/")),
+		Exported:  exported,
+		Func:      "run" + exported,
+		Key:       strings.Join(segments, "."),
+		File:      strings.ToLower(strings.ReplaceAll(strings.Join(segments, "_"), "-", "_")),
+		Args:      strings.Join(quoted, ", "),
+		ParentVar: commandVar(parent),
+	}, nil
+}
+
+// commandVar returns the name of the variable of the command at the slash
+// separated path, rootCmd for the empty path.
+func commandVar(cmdPath string) string {
+	if cmdPath == "" || cmdPath == "root" {
+		return "rootCmd"
+	}
+
+	exported := ""
+	for _, s := range strings.Split(cmdPath, "/") {
+		exported += exportedName(s)
+	}
+	return strings.ToLower(exported[:1]) + exported[1:] + "Cmd"
+}
+
+// commandSource produces the files of a cobra command.
+type commandSource struct {
+	cmd CommandData
+}
+
+// Name returns the name of the source.
+func (s *commandSource) Name() string {
+	return "component:command"
+}
+
+// Entries returns the rendered command file and its test.
+func (s *commandSource) Entries(opts Options) ([]Entry, error) {
+	d := NewTemplateData(opts)
+	d.Command = s.cmd
+
+	return templateEntries(data, path.Join("data", "components", "command"), d)
+}
+
+// NewCommand returns a generator adding the cobra command described by cmd to
+// the cmd directory of the project at opts.Location. The command still needs
+// to be registered with its parent using RegisterCommand.
+func NewCommand(opts Options, cmd CommandData) *Generator {
+	return &Generator{
+		Options: opts,
+		Sources: []Source{&commandSource{cmd: cmd}},
+		Sink:    NewDirSink(opts.Location),
+	}
+}
+
+// ParentFile returns the path of the file of the cmd directory of fsys
+// declaring the variable of the parent of the command. It fails when no file
+// declares it, so the parent can be checked before the command is generated.
+func ParentFile(fsys vfs.FS, cmd CommandData) (string, error) {
+	infos, err := afero.ReadDir(fsys, "cmd")
+	if err != nil {
+		return "", err
+	}
+
+	var names []string
+	for _, info := range infos {
+		if !info.IsDir() && strings.HasSuffix(info.Name(), ".go") && !strings.HasSuffix(info.Name(), "_test.go") {
+			names = append(names, info.Name())
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		file := filepath.Join("cmd", name)
+
+		src, err := vfs.ReadFile(fsys, file)
+		if err != nil {
+			return "", err
+		}
+
+		f, err := parser.ParseFile(token.NewFileSet(), file, src, 0)
+		if err != nil {
+			return "", err
+		}
+
+		if project.DeclaresVar(f, cmd.ParentVar) {
+			return filepath.ToSlash(file), nil
+		}
+	}
+
+	if i := strings.LastIndex(cmd.Path, " "); i >= 0 {
+		return "", fmt.Errorf("parent command %s not declared in any file of cmd/, add it first with go-setup add command %s", cmd.ParentVar, strings.ReplaceAll(cmd.Path[:i], " ", "/"))
+	}
+	return "", fmt.Errorf("parent command %s not declared in any file of cmd/", cmd.ParentVar)
+}
+
+// RegisterCommand adds the command to its parent by editing the file of the
+// cmd directory of fsys declaring the parent variable: a call to AddCommand is
+// appended to the init function of that file, which is created if needed.
+// It returns the path of the edited file, or an empty path when the command
+// was already registered.
+func RegisterCommand(fsys vfs.FS, cmd CommandData) (string, error) {
+	file, err := ParentFile(fsys, cmd)
+	if err != nil {
+		return "", err
+	}
+
+	src, err := vfs.ReadFile(fsys, file)
+	if err != nil {
+		return "", err
+	}
+
+	updated, err := addCommandCall(src, cmd.ParentVar, cmd.Var)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", file, err)
+	}
+	if updated == nil {
+		return "", nil
+	}
+
+	info, err := fsys.Stat(file)
+	if err != nil {
+		return "", err
+	}
+	return file, vfs.WriteFile(fsys, file, updated, info.Mode().Perm())
+}
+
+// addCommandCall inserts parentVar.AddCommand(childVar) at the end of the init
+// function of src, which declares parentVar. updated is nil when the call is
+// already present.
+func addCommandCall(src []byte, parentVar, childVar string) (updated []byte, err error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	var initFunc *ast.FuncDecl
+	registered := false
+
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			if n.Name.Name == "init" && n.Recv == nil && n.Body != nil && initFunc == nil {
+				initFunc = n
+			}
+		case *ast.CallExpr:
+			if isAddCommandCall(n, parentVar, childVar) {
+				registered = true
+			}
+		}
+		return true
+	})
+
+	if registered {
+		return nil, nil
+	}
+
+	call := parentVar + ".AddCommand(" + childVar + ")"
+
+	// insert as text at the offset found in the syntax tree, so the comments stay where they are
+	var buf bytes.Buffer
+	if initFunc != nil {
+		offset := fset.Position(initFunc.Body.Rbrace).Offset
+		buf.Write(bytes.TrimRight(src[:offset], " \t\n"))
+		buf.WriteString("\n" + call + "\n")
+		buf.Write(src[offset:])
+	} else {
+		buf.Write(bytes.TrimRight(src, "\n"))
+		buf.WriteString("\n\nfunc init() {\n" + call + "\n}\n")
+	}
+
+	return format.Source(buf.Bytes())
+}
+
+// isAddCommandCall reports whether call is parentVar.AddCommand(..., childVar, ...).
+func isAddCommandCall(call *ast.CallExpr, parentVar, childVar string) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "AddCommand" {
+		return false
+	}
+
+	if x, ok := sel.X.(*ast.Ident); !ok || x.Name != parentVar {
+		return false
+	}
+
+	for _, arg := range call.Args {
+		if ident, ok := arg.(*ast.Ident); ok && ident.Name == childVar {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package scaffold
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dark-shade/go-setup/pkg/vfs"
+)
+
+const rootSrc = `package cmd
+
+import "github.com/spf13/cobra"
+
+var rootCmd = &cobra.Command{Use: "app"}
+
+func init() {
+	// flags of the root command
+	rootCmd.PersistentFlags().String("config", "", "config file")
+}
+`
+
+const configSrc = `package cmd
+
+import "github.com/spf13/cobra"
+
+var configCmd = &cobra.Command{Use: "config"}
+`
+
+func TestRegisterCommand(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		path  string
+		// want is the edited file, empty when the command is already registered
+		want string
+		// contains is expected in the edited file
+		contains []string
+		err      string
+	}{
+		{
+			name:     "appended to init",
+			files:    map[string]string{"cmd/root.go": rootSrc},
+			path:     "serve",
+			want:     "cmd/root.go",
+			contains: []string{"\t// flags of the root command\n", "\trootCmd.AddCommand(serveCmd)\n}\n"},
+		},
+		{
+			name:     "init created",
+			files:    map[string]string{"cmd/root.go": rootSrc, "cmd/config.go": configSrc},
+			path:     "config/set",
+			want:     "cmd/config.go",
+			contains: []string{"func init() {\n\tconfigCmd.AddCommand(configSetCmd)\n}\n"},
+		},
+		{
+			name:  "already registered",
+			files: map[string]string{"cmd/root.go": strings.Replace(rootSrc, "\n}\n", "\n\trootCmd.AddCommand(serveCmd)\n}\n", 1)},
+			path:  "serve",
+		},
+		{
+			name:  "test files ignored",
+			files: map[string]string{"cmd/root.go": rootSrc, "cmd/config_test.go": configSrc},
+			path:  "config/set",
+			err:   "parent command configCmd not declared in any file of cmd/, add it first with go-setup add command config",
+		},
+		{
+			name:  "missing parent",
+			files: map[string]string{"cmd/root.go": rootSrc},
+			path:  "config/set",
+			err:   "parent command configCmd not declared",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := vfs.Memory()
+			for name, src := range tt.files {
+				if err := vfs.WriteFile(fsys, name, []byte(src), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			cmd, err := NewCommandData(tt.path, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := RegisterCommand(fsys, cmd)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("got error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tt.want {
+				t.Fatalf("got edited file %q, want %q", got, tt.want)
+			}
+			if got == "" {
+				return
+			}
+
+			data, err := vfs.ReadFile(fsys, got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range tt.contains {
+				if !strings.Contains(string(data), c) {
+					t.Errorf("%s does not contain %q:\n%s", got, c, data)
+				}
+			}
+		})
+	}
+}
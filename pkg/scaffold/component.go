@@ -0,0 +1,135 @@
+package scaffold
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dark-shade/go-setup/pkg/project"
+)
+
+// Component is a kind of component that can be added to an existing project.
+type Component struct {
+	Kind        string
+	Description string
+}
+
+// components are the kinds of components known to go-setup, their templates live in data/components/<kind>.
+var components = map[string]Component{
+	"cmd":        {Kind: "cmd", Description: "main package of a binary in cmd/<name>"},
+	"command":    {Kind: "command", Description: "cobra command of a cli project in cmd/, <name> is a path like config/set"},
+	"pkg":        {Kind: "pkg", Description: "public package in pkg/<name>"},
+	"internal":   {Kind: "internal", Description: "private package in internal/<name>"},
+	"handler":    {Kind: "handler", Description: "HTTP handler in internal/handler"},
+	"middleware": {Kind: "middleware", Description: "HTTP middleware in internal/middleware"},
+	"config":     {Kind: "config", Description: "environment configuration in internal/config and configs/"},
+	"init":       {Kind: "init", Description: "init system configuration of a cmd/ binary in init/, add init <" + strings.Join(initSystems, "|") + "> <binary>"},
+}
+
+// validComponentName matches the names allowed for components.
+var validComponentName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// Components returns the known kinds of components sorted by kind.
+func Components() []Component {
+	list := make([]Component, 0, len(components))
+	for _, c := range components {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Kind < list[j].Kind })
+	return list
+}
+
+// LookupComponent returns the kind of component and whether it exists.
+func LookupComponent(kind string) (Component, bool) {
+	c, ok := components[kind]
+	return c, ok
+}
+
+// ComponentData is the data of the component available to the component templates.
+type ComponentData struct {
+	// Name is the name given by the user, e.g. user-store.
+	Name string
+	// Package is the name usable as a go package, e.g. userstore.
+	Package string
+	// Exported is the name usable as an exported identifier, e.g. UserStore.
+	Exported string
+	// Env is the prefix of the environment variables, e.g. USERSTORE.
+	Env string
+}
+
+// NewComponentData returns the template data of the component name.
+func NewComponentData(name string) ComponentData {
+	pkg := project.PackageName(name)
+	return ComponentData{
+		Name:     name,
+		Package:  pkg,
+		Exported: exportedName(name),
+		Env:      strings.ToUpper(pkg),
+	}
+}
+
+// componentSource produces the files of a component.
+type componentSource struct {
+	kind string
+	name string
+}
+
+// NewComponentSource returns the source of the component of the given kind and name.
+func NewComponentSource(kind, name string) (Source, error) {
+	if _, ok := components[kind]; !ok {
+		var kinds []string
+		for _, c := range Components() {
+			kinds = append(kinds, c.Kind)
+		}
+		return nil, fmt.Errorf("invalid kind: %s. Valid values are %s", kind, strings.Join(kinds, ", "))
+	}
+
+	// init configurations are for a binary, see NewInitSource
+	if kind == "init" {
+		return nil, fmt.Errorf("init needs an init system and a binary, e.g. add init systemd %s", name)
+	}
+
+	if kind == "command" {
+		cmd, err := NewCommandData(name, "")
+		if err != nil {
+			return nil, err
+		}
+		return &commandSource{cmd: cmd}, nil
+	}
+
+	if !validComponentName.MatchString(name) {
+		return nil, fmt.Errorf("invalid name: %s. Names start with a letter followed by letters, digits, - or _", name)
+	}
+
+	return &componentSource{kind: kind, name: name}, nil
+}
+
+// Name returns the name of the source.
+func (s *componentSource) Name() string {
+	return "component:" + s.kind
+}
+
+// Entries returns the rendered templates of the component.
+func (s *componentSource) Entries(opts Options) ([]Entry, error) {
+	d := NewTemplateData(opts)
+	d.Component = NewComponentData(s.name)
+
+	return templateEntries(data, path.Join("data", "components", s.kind), d)
+}
+
+// NewComponent returns a generator adding the component of the given kind and
+// name to the project at opts.Location, whose module path is opts.ModulePath.
+func NewComponent(opts Options, kind, name string) (*Generator, error) {
+	src, err := NewComponentSource(kind, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Generator{
+		Options: opts,
+		Sources: []Source{src},
+		Sink:    NewDirSink(opts.Location),
+	}, nil
+}
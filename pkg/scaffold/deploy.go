@@ -0,0 +1,119 @@
+package scaffold
+
+import (
+	"path"
+	"strings"
+)
+
+// deployTargets are the supported kinds of deployment manifests.
+var deployTargets = []string{"helm", "k8s", "kustomize"}
+
+// DeployData is the data of the deployment manifest templates, which use
+// [[ ]] delimiters as helm charts use {{ }}.
+type DeployData struct {
+	// App is the name of the kubernetes resources, a DNS label, e.g. my-app.
+	App string
+	// Image is the container image without tag, e.g. ghcr.io/jane/my-app.
+	Image string
+	// Port is the port the service listens on.
+	Port int
+	// PortName is the name of the container port, http or grpc.
+	PortName string
+	// GRPC is set for gRPC services, probed with the gRPC health service.
+	GRPC bool
+}
+
+// validDeploy reports whether the kind of deployment manifests is supported.
+func validDeploy(kind string) bool {
+	for _, k := range deployTargets {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// newDeployData returns the deployment data of the service generated for the options.
+func newDeployData(opts Options, d TemplateData) DeployData {
+	preset, _ := LookupPreset(opts.Type)
+	dd := DeployData{App: dnsLabel(d.Name), Image: imageName(d.ModulePath, d.Name), Port: preset.Port, PortName: "http"}
+	if preset.Name == "grpc-service" {
+		dd.GRPC = true
+		dd.PortName = "grpc"
+	}
+	return dd
+}
+
+// dnsLabel returns name lower cased with every character not allowed in a
+// DNS label replaced by a dash.
+func dnsLabel(name string) string {
+	label := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '-'
+	}, strings.ToLower(name))
+
+	label = strings.Trim(label, "-")
+	if label == "" {
+		return "app"
+	}
+	return label
+}
+
+// imageName returns the container image of the project: the GitHub container
+// registry for GitHub modules, the project name otherwise.
+func imageName(modulePath, name string) string {
+	elems := strings.Split(modulePath, "/")
+	if len(elems) >= 3 && elems[0] == "github.com" {
+		return strings.ToLower("ghcr.io/" + elems[1] + "/" + elems[2])
+	}
+	return dnsLabel(name)
+}
+
+// deploySource produces the deployment manifests of services under deployments/.
+type deploySource struct{}
+
+// NewDeploySource returns the source of the kubernetes manifests, helm chart or kustomize base and overlays.
+func NewDeploySource() Source {
+	return &deploySource{}
+}
+
+// Name returns the name of the source.
+func (s *deploySource) Name() string {
+	return "deploy"
+}
+
+// Entries returns the manifests of the kind of the options, none when the options have no deploy kind.
+func (s *deploySource) Entries(opts Options) ([]Entry, error) {
+	if opts.Deploy == "" {
+		return nil, nil
+	}
+
+	d := NewTemplateData(opts)
+	d.Deploy = newDeployData(opts, d)
+
+	// the k8s manifests are also the base of kustomize
+	var dirs [][2]string
+	switch opts.Deploy {
+	case "k8s":
+		dirs = [][2]string{{"manifests", "deployments/k8s"}}
+	case "kustomize":
+		dirs = [][2]string{{"manifests", "deployments/kustomize/base"}, {"kustomize", "deployments/kustomize"}}
+	case "helm":
+		dirs = [][2]string{{"helm", path.Join("deployments/helm", d.Deploy.App)}}
+	}
+
+	var entries []Entry
+	for _, dir := range dirs {
+		files, err := templateEntriesDelims(data, path.Join("data", "deploy", dir[0]), d, "[[", "]]")
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range files {
+			e.Path = path.Join(dir[1], e.Path)
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,49 @@
+package scaffold
+
+import (
+	"github.com/dark-shade/go-setup/pkg/docker"
+	"github.com/dark-shade/go-setup/pkg/project"
+)
+
+// dockerConfig returns the image build configuration of the project generated for the options.
+func dockerConfig(opts Options) docker.Config {
+	cfg := docker.Config{GoVersion: GoVersion(), CGO: opts.CGO, Runtime: opts.Runtime, VersionVar: versionVar(opts)}
+
+	name := NewTemplateData(opts).Name
+	preset, ok := LookupPreset(opts.Type)
+	switch {
+	case ok && preset.Binary:
+		cfg.Binaries = []project.Binary{{Name: name, Package: "./cmd/" + name}}
+	case !ok || preset.Name == "cli":
+		cfg.Binaries = []project.Binary{{Name: name, Package: "."}}
+	}
+
+	// the main packages of the service presets check their own health, the
+	// runtime images have no shell nor http client
+	if preset.Service {
+		cfg.Port = preset.Port
+		cfg.HealthCheck = []string{"-healthcheck"}
+	}
+
+	return cfg
+}
+
+// dockerEntries returns the Dockerfile and .dockerignore of the project, none
+// for projects without main package.
+func dockerEntries(opts Options) ([]Entry, error) {
+	cfg := dockerConfig(opts)
+	if len(cfg.Binaries) == 0 {
+		return nil, nil
+	}
+
+	files, err := docker.Render(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(files))
+	for _, f := range files {
+		entries = append(entries, Entry{Kind: FileEntry, Path: f.Path, Data: f.Data, Mode: fileMode})
+	}
+	return entries, nil
+}
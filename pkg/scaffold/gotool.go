@@ -0,0 +1,25 @@
+package scaffold
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Tidy runs go mod tidy in dir, so the go.sum of a generated project is
+// complete and it builds right away. It needs the go toolchain in the PATH.
+func Tidy(dir string) error {
+	cmd := exec.Command("go", "mod", "tidy")
+	cmd.Dir = dir
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("go mod tidy: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// NeedsTidy reports whether the project generated with opts has dependencies to download.
+func NeedsTidy(opts Options) bool {
+	preset, ok := LookupPreset(opts.Type)
+	return ok && len(preset.Requires) > 0
+}
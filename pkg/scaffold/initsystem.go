@@ -0,0 +1,80 @@
+package scaffold
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// initSystems are the init systems whose configuration can be added, their
+// templates live in data/components/init/<system>.
+var initSystems = []string{"openrc", "supervisord", "systemd"}
+
+// InitSystems returns the names of the supported init systems.
+func InitSystems() []string {
+	return append([]string(nil), initSystems...)
+}
+
+// initSource produces the init system configuration of a binary.
+type initSource struct {
+	system string
+	binary string
+}
+
+// NewInitSource returns the source of the configuration of the init system
+// running the binary of cmd/<binary>.
+func NewInitSource(system, binary string) (Source, error) {
+	valid := false
+	for _, s := range initSystems {
+		valid = valid || s == system
+	}
+	if !valid {
+		return nil, fmt.Errorf("invalid init system: %s. Valid values are %s", system, strings.Join(initSystems, ", "))
+	}
+
+	if !validComponentName.MatchString(binary) {
+		return nil, fmt.Errorf("invalid binary: %s. Names start with a letter followed by letters, digits, - or _", binary)
+	}
+
+	return &initSource{system: system, binary: binary}, nil
+}
+
+// Name returns the name of the source.
+func (s *initSource) Name() string {
+	return "init:" + s.system
+}
+
+// Entries returns the rendered configuration in init/<system>. The openrc
+// service scripts are executable.
+func (s *initSource) Entries(opts Options) ([]Entry, error) {
+	d := NewTemplateData(opts)
+	d.Component = NewComponentData(s.binary)
+
+	entries, err := templateEntries(data, path.Join("data", "components", "init", s.system), d)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range entries {
+		entries[i].Path = path.Join("init", s.system, entries[i].Path)
+		if s.system == "openrc" {
+			entries[i].Mode = 0755
+		}
+	}
+	return entries, nil
+}
+
+// NewInit returns a generator adding the configuration of the init system
+// for the binary to the project at opts.Location.
+func NewInit(opts Options, system, binary string) (*Generator, error) {
+	src, err := NewInitSource(system, binary)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Generator{
+		Options: opts,
+		Sources: []Source{src},
+		Sink:    NewDirSink(opts.Location),
+	}, nil
+}
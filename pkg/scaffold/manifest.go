@@ -0,0 +1,191 @@
+package scaffold
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/dark-shade/go-setup/pkg/vfs"
+	"gopkg.in/yaml.v2"
+)
+
+// ManifestDir is the directory of a generated project where go-setup keeps
+// the manifest and the base content of the generated files.
+const ManifestDir = ".go-setup"
+
+// manifestVersion is the version of the manifest format.
+const manifestVersion = 1
+
+var (
+	manifestPath = path.Join(ManifestDir, "manifest.yaml")
+	baseDir      = path.Join(ManifestDir, "base")
+)
+
+// unmanaged are the generated files owned by other tools once created, they are not recorded.
+var unmanaged = map[string]bool{"go.mod": true}
+
+// Manifest records the inputs of a generation and the files it created, so
+// the project can later be compared to and upgraded with newer templates.
+type Manifest struct {
+	Version int            `yaml:"version"`
+	Options Options        `yaml:"options"`
+	Files   []ManifestFile `yaml:"files"`
+}
+
+// ManifestFile is a generated file whose content at generation time is kept in .go-setup/base.
+type ManifestFile struct {
+	Path   string `yaml:"path"`
+	Source string `yaml:"source"`
+	SHA256 string `yaml:"sha256"`
+}
+
+// NewManifest returns the manifest of the files of the plan created by the apply.
+func NewManifest(plan *Plan, res *Result) *Manifest {
+	created := make(map[string]bool, len(res.Created))
+	for _, p := range res.Created {
+		created[p] = true
+	}
+
+	// the module path is resolved so the project keeps it when its directory is renamed
+	opts := plan.Options
+	opts.ModulePath = ModulePath(opts)
+
+	m := &Manifest{Version: manifestVersion, Options: opts}
+	for _, e := range plan.Entries {
+		if e.Kind == FileEntry && created[e.Path] && !unmanaged[e.Path] {
+			m.Files = append(m.Files, ManifestFile{Path: e.Path, Source: e.Source, SHA256: checksum(e.Data)})
+		}
+	}
+
+	return m
+}
+
+// checksum returns the hex encoded sha256 of data.
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Lookup returns the recorded file for the path and whether it is recorded.
+func (m *Manifest) Lookup(name string) (ManifestFile, bool) {
+	for _, f := range m.Files {
+		if f.Path == name {
+			return f, true
+		}
+	}
+	return ManifestFile{}, false
+}
+
+// Set records the file, replacing the record of the same path if any.
+func (m *Manifest) Set(file ManifestFile) {
+	for i, f := range m.Files {
+		if f.Path == file.Path {
+			m.Files[i] = file
+			return
+		}
+	}
+	m.Files = append(m.Files, file)
+}
+
+// Remove removes the record of the path.
+func (m *Manifest) Remove(name string) {
+	for i, f := range m.Files {
+		if f.Path == name {
+			m.Files = append(m.Files[:i], m.Files[i+1:]...)
+			return
+		}
+	}
+}
+
+// Merge adds the files of old which are not recorded in m.
+func (m *Manifest) Merge(old *Manifest) {
+	for _, f := range old.Files {
+		if _, ok := m.Lookup(f.Path); !ok {
+			m.Files = append(m.Files, f)
+		}
+	}
+}
+
+// ReadManifest reads the manifest of the project at the root of fsys.
+func ReadManifest(fsys vfs.FS) (*Manifest, error) {
+	raw, err := vfs.ReadFile(fsys, filepath.FromSlash(manifestPath))
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{}
+	if err := yaml.Unmarshal(raw, m); err != nil {
+		return nil, fmt.Errorf("%s: %w", manifestPath, err)
+	}
+
+	if m.Version != manifestVersion {
+		return nil, fmt.Errorf("%s: unsupported manifest version %d", manifestPath, m.Version)
+	}
+
+	return m, nil
+}
+
+// ReadBase returns the content the file had when it was generated.
+func ReadBase(fsys vfs.FS, name string) ([]byte, error) {
+	return vfs.ReadFile(fsys, filepath.FromSlash(path.Join(baseDir, name)))
+}
+
+// WriteManifest writes the manifest, and the base content of every file of
+// the plan it records, to the sink.
+func WriteManifest(sink Sink, m *Manifest, plan *Plan) error {
+	sort.Slice(m.Files, func(i, j int) bool { return m.Files[i].Path < m.Files[j].Path })
+
+	for _, f := range m.Files {
+		e, ok := plan.Lookup(f.Path)
+		if !ok {
+			continue
+		}
+
+		if err := sink.WriteFile(path.Join(baseDir, f.Path), e.Data, fileMode); err != nil {
+			return err
+		}
+	}
+
+	return writeManifestFile(sink, m)
+}
+
+// writeManifestFile writes the manifest itself to the sink.
+func writeManifestFile(sink Sink, m *Manifest) error {
+	raw, err := yaml.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	return sink.WriteFile(manifestPath, raw, fileMode)
+}
+
+// UpdateBase replaces the recorded content of every file of the manifest with
+// the result of update, called with the slash separated path of the file,
+// then writes the manifest of the project at the root of fsys. It keeps the
+// base in line with changes made to the whole project, e.g. a module rename,
+// which the next upgrade must not merge as template changes.
+func UpdateBase(fsys vfs.FS, m *Manifest, update func(name string, data []byte) []byte) error {
+	sink := NewFSSink(fsys)
+
+	for i, f := range m.Files {
+		data, err := ReadBase(fsys, f.Path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		data = update(f.Path, data)
+		if err := sink.WriteFile(path.Join(baseDir, f.Path), data, fileMode); err != nil {
+			return err
+		}
+		m.Files[i].SHA256 = checksum(data)
+	}
+
+	return writeManifestFile(sink, m)
+}
@@ -0,0 +1,122 @@
+package scaffold
+
+import (
+	"path"
+	"sort"
+)
+
+// Preset is a kind of project with its own layout and starter code.
+type Preset struct {
+	Name        string
+	Description string
+	// Service is true for presets exposing network endpoints.
+	Service bool
+	// Port is the default port services listen on.
+	Port int
+	// Binary reports whether the main package is in cmd/<name> rather than in the root.
+	Binary bool
+	// Requires are the modules imported by the starter code, as module@version.
+	Requires []string
+}
+
+// presets are the project types known to go-setup, their templates live in data/presets/<name>.
+var presets = map[string]Preset{
+	"cli": {
+		Name:        "cli",
+		Description: "cobra command line application with root and version commands",
+		Requires:    []string{"github.com/spf13/cobra@v1.10.2", "github.com/spf13/viper@v1.21.0"},
+	},
+	"http-service": {
+		Name:        "http-service",
+		Description: "HTTP server with graceful shutdown and health endpoints",
+		Service:     true,
+		Port:        8080,
+		Binary:      true,
+	},
+	"grpc-service": {
+		Name:        "grpc-service",
+		Description: "gRPC server with a proto definition, health and reflection services",
+		Service:     true,
+		Port:        9090,
+		Binary:      true,
+		Requires:    []string{"google.golang.org/grpc@v1.84.0"},
+	},
+	"library": {
+		Name:        "library",
+		Description: "importable package with doc.go and an example test",
+	},
+	"worker": {
+		Name:        "worker",
+		Description: "background worker running a job at a fixed interval",
+		Binary:      true,
+	},
+}
+
+// Presets returns the known presets sorted by name.
+func Presets() []Preset {
+	list := make([]Preset, 0, len(presets))
+	for _, p := range presets {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// presetNames returns the sorted names of the known presets.
+func presetNames() []string {
+	var names []string
+	for _, p := range Presets() {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// serviceNames returns the sorted names of the service presets.
+func serviceNames() []string {
+	var names []string
+	for _, p := range Presets() {
+		if p.Service {
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
+
+// LookupPreset returns the preset with the given name and whether it exists.
+func LookupPreset(name string) (Preset, bool) {
+	p, ok := presets[name]
+	return p, ok
+}
+
+// presetSource produces the starter code of the preset selected by the options.
+type presetSource struct{}
+
+// NewPresetSource returns the source of the starter code of the project type.
+func NewPresetSource() Source {
+	return &presetSource{}
+}
+
+// Name returns the name of the source.
+func (s *presetSource) Name() string {
+	return "preset"
+}
+
+// Entries returns the rendered templates of the preset, or nothing when no type is set.
+// The presets with a main package get the internal/version package.
+func (s *presetSource) Entries(opts Options) ([]Entry, error) {
+	if opts.Type == "" {
+		return nil, nil
+	}
+
+	d := NewTemplateData(opts)
+	entries, err := templateEntries(data, path.Join("data", "presets", opts.Type), d)
+	if err != nil || versionVar(opts) == "" {
+		return entries, err
+	}
+
+	version, err := templateEntries(data, path.Join("data", "version"), d)
+	if err != nil {
+		return nil, err
+	}
+	return append(entries, version...), nil
+}
@@ -0,0 +1,101 @@
+package scaffold
+
+import (
+	"path"
+	"strings"
+
+	"github.com/dark-shade/go-setup/pkg/packaging"
+	"github.com/dark-shade/go-setup/pkg/task"
+)
+
+// validRelease reports whether the release tool is supported.
+func validRelease(tool string) bool {
+	for _, t := range packaging.Tools {
+		if t == tool {
+			return true
+		}
+	}
+	return false
+}
+
+// releaseConfig returns the release configuration of the project generated
+// for the options. The binaries of the presets in cmd/ run as systemd units.
+func releaseConfig(opts Options) packaging.Config {
+	data := NewTemplateData(opts)
+	cfg := packaging.Config{
+		Name:       data.Name,
+		Module:     data.ModulePath,
+		VersionVar: taskConfig(opts).VersionVar,
+		CGO:        opts.CGO,
+		Files:      []string{"README.md", "CHANGELOG.md"},
+		Maintainer: maintainer(opts.Author),
+		License:    packaging.License(opts.License),
+	}
+	if opts.License != "" {
+		cfg.Files = append([]string{"LICENSE"}, cfg.Files...)
+	}
+
+	preset, _ := LookupPreset(opts.Type)
+	for _, b := range dockerConfig(opts).Binaries {
+		b.Unit = preset.Binary
+		cfg.Binaries = append(cfg.Binaries, b)
+	}
+
+	for _, p := range task.Platforms {
+		cfg.Platforms = append(cfg.Platforms, packaging.Platform{OS: path.Dir(p), Arch: path.Base(p)})
+	}
+	return cfg
+}
+
+// maintainer returns the author as a package maintainer, Name <email>.
+func maintainer(author string) string {
+	i := strings.LastIndex(author, " ")
+	if i < 0 || !strings.Contains(author[i+1:], "@") || strings.HasPrefix(author[i+1:], "<") {
+		return author
+	}
+	return author[:i] + " <" + author[i+1:] + ">"
+}
+
+// releaseSource produces the release configuration of the release tool of the options.
+type releaseSource struct{}
+
+// NewReleaseSource returns the source of the goreleaser and nfpm configurations,
+// and of the systemd units of the binaries packaged by nfpm.
+func NewReleaseSource() Source {
+	return &releaseSource{}
+}
+
+// Name returns the name of the source.
+func (s *releaseSource) Name() string {
+	return "release"
+}
+
+// Entries returns the release configuration, none when the options have no release tool.
+func (s *releaseSource) Entries(opts Options) ([]Entry, error) {
+	if opts.Release == "" {
+		return nil, nil
+	}
+
+	cfg := releaseConfig(opts)
+
+	var entries []Entry
+	for _, f := range packaging.Render(cfg) {
+		mode := fileMode
+		if f.Executable {
+			mode = 0755
+		}
+		entries = append(entries, Entry{Kind: FileEntry, Path: f.Path, Data: f.Data, Mode: mode})
+	}
+
+	for _, b := range cfg.Binaries {
+		if !b.Unit {
+			continue
+		}
+		units, err := (&initSource{system: "systemd", binary: b.Name}).Entries(opts)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, units...)
+	}
+	return entries, nil
+}
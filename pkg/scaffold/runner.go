@@ -0,0 +1,35 @@
+package scaffold
+
+import (
+	"github.com/dark-shade/go-setup/pkg/task"
+)
+
+// taskConfig returns the task configuration of the project generated for the options.
+func taskConfig(opts Options) task.Config {
+	data := NewTemplateData(opts)
+	cfg := task.Config{Name: data.Name, Binaries: dockerConfig(opts).Binaries}
+
+	cfg.VersionVar = versionVar(opts)
+
+	// the Dockerfile is part of the operations files
+	cfg.Docker = (opts.Full || opts.Ops) && len(cfg.Binaries) > 0
+	cfg.Goreleaser = opts.Release == "goreleaser"
+	return cfg
+}
+
+// versionVar returns the variable the version of the binaries is set to, the
+// Version of the internal/version package of the presets with a main package.
+// The bare main package has no version variable.
+func versionVar(opts Options) string {
+	preset, _ := LookupPreset(opts.Type)
+	if preset.Name != "cli" && !preset.Binary {
+		return ""
+	}
+	return ModulePath(opts) + "/internal/version.Version"
+}
+
+// runnerEntry returns the file of the task runner of the project, the Makefile by default.
+func runnerEntry(opts Options) Entry {
+	runner, _ := task.LookupRunner(opts.Runner)
+	return Entry{Kind: FileEntry, Path: runner.File, Data: runner.Render(task.New(taskConfig(opts))), Mode: fileMode}
+}
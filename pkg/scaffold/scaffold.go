@@ -0,0 +1,300 @@
+// Package scaffold generates golang project structures that loosely follow
+// https://github.com/golang-standards/project-layout.
+//
+// A Generator collects entries from its sources into a Plan, and applies the
+// plan to a Sink. The init command of go-setup is a thin wrapper over it, so the
+// same projects can be generated from Go code.
+package scaffold
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dark-shade/go-setup/pkg/ci"
+	"github.com/dark-shade/go-setup/pkg/packaging"
+	"github.com/dark-shade/go-setup/pkg/task"
+)
+
+// Options are the inputs of a project generation. They are recorded in the
+// manifest of the project, except for the location and the profiles directory.
+type Options struct {
+	// Location is the directory in which the project is set up.
+	Location string `yaml:"-"`
+	// License is the license to add, either mit or apache, or empty for none.
+	License string `yaml:"license,omitempty"`
+	// Author is the name and email of the author, e.g. Jane Doe jane.doe@gmail.com.
+	Author string `yaml:"author,omitempty"`
+	// ModulePath is the module path written to go.mod.
+	ModulePath string `yaml:"modulePath,omitempty"`
+	// Profiles are the names of the profiles to copy into the project.
+	Profiles []string `yaml:"profiles,omitempty"`
+	// ProfilesDir is the directory containing the profiles, usually ~/.go-setup/profiles.
+	ProfilesDir string `yaml:"-"`
+	// Full adds all files and directories of the recommended layout.
+	Full bool `yaml:"full,omitempty"`
+	// Ops adds the operations related files.
+	Ops bool `yaml:"ops,omitempty"`
+	// Type is the name of the project preset, empty for a bare main package.
+	Type string `yaml:"type,omitempty"`
+	// CI is the name of the CI provider to generate the pipeline of, e.g. github, empty for none.
+	CI string `yaml:"ci,omitempty"`
+	// Deploy is the kind of deployment manifests of services, k8s, helm or kustomize, empty for none.
+	Deploy string `yaml:"deploy,omitempty"`
+	// Release is the release tool to generate the configuration of, goreleaser, empty for none.
+	Release string `yaml:"release,omitempty"`
+	// Runner is the task runner to generate the file of, make, task or just, empty for make.
+	Runner string `yaml:"runner,omitempty"`
+	// Runtime is the runtime image of the Dockerfile, distroless or scratch, empty for the default one.
+	Runtime string `yaml:"runtime,omitempty"`
+	// CGO builds the binaries of the Dockerfile with cgo enabled.
+	CGO bool `yaml:"cgo,omitempty"`
+}
+
+// Validate checks that the options can be used for a generation.
+func (o Options) Validate() error {
+	if o.Location == "" {
+		return errors.New("location to initialize project is empty")
+	}
+
+	if o.License != "" && o.License != "mit" && o.License != "apache" {
+		return fmt.Errorf("invalid license: %s. Valid values are mit or apache", o.License)
+	}
+
+	if _, ok := LookupPreset(o.Type); o.Type != "" && !ok {
+		return fmt.Errorf("invalid type: %s. Valid values are %s", o.Type, strings.Join(presetNames(), ", "))
+	}
+
+	if o.CI != "" && !validCI(o.CI) {
+		return fmt.Errorf("invalid ci: %s. Valid values are %s", o.CI, strings.Join(ci.Providers(), ", "))
+	}
+
+	if o.Deploy != "" {
+		if !validDeploy(o.Deploy) {
+			return fmt.Errorf("invalid deploy: %s. Valid values are %s", o.Deploy, strings.Join(deployTargets, ", "))
+		}
+		if preset, _ := LookupPreset(o.Type); !preset.Service {
+			return fmt.Errorf("deploy %s needs a service type: %s", o.Deploy, strings.Join(serviceNames(), ", "))
+		}
+	}
+
+	if o.Release != "" {
+		if !validRelease(o.Release) {
+			return fmt.Errorf("invalid release: %s. Valid values are %s", o.Release, strings.Join(packaging.Tools, ", "))
+		}
+		if len(dockerConfig(o).Binaries) == 0 {
+			return fmt.Errorf("release %s needs a type with a main package", o.Release)
+		}
+	}
+
+	if _, ok := task.LookupRunner(o.Runner); !ok {
+		return fmt.Errorf("invalid runner: %s. Valid values are %s", o.Runner, strings.Join(task.Runners(), ", "))
+	}
+
+	if err := dockerConfig(o).Validate(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// EntryKind is the kind of an entry of a plan.
+type EntryKind int
+
+const (
+	// DirEntry is a directory.
+	DirEntry EntryKind = iota
+	// FileEntry is a regular file.
+	FileEntry
+	// SymlinkEntry is a symbolic link, its data is the link target.
+	SymlinkEntry
+)
+
+// String returns the name of the kind.
+func (k EntryKind) String() string {
+	switch k {
+	case DirEntry:
+		return "dir"
+	case SymlinkEntry:
+		return "symlink"
+	default:
+		return "file"
+	}
+}
+
+// Entry is a single directory or file of a generated project.
+type Entry struct {
+	Kind EntryKind
+	// Path is slash separated and relative to the project location.
+	Path string
+	// Data is the content of a file entry.
+	Data []byte
+	Mode fs.FileMode
+	// Source is the name of the source which produced the entry.
+	Source string
+}
+
+// Plan is the ordered list of entries a generation would create.
+type Plan struct {
+	Options Options
+	Entries []Entry
+}
+
+// Lookup returns the entry for the given path and whether it is part of the plan.
+func (p *Plan) Lookup(name string) (Entry, bool) {
+	for _, e := range p.Entries {
+		if e.Path == name {
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Paths returns the sorted paths of all entries of the plan.
+func (p *Plan) Paths() []string {
+	paths := make([]string, 0, len(p.Entries))
+	for _, e := range p.Entries {
+		paths = append(paths, e.Path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Result is the outcome of applying a plan.
+type Result struct {
+	// Created are the paths created by the apply.
+	Created []string
+	// Skipped are the paths that already existed and were left untouched.
+	Skipped []string
+	// Errors are the non-fatal errors that happened during the apply.
+	Errors []error
+}
+
+// Generator builds plans from its sources and applies them to its sink.
+type Generator struct {
+	Options Options
+	Sources []Source
+	Sink    Sink
+}
+
+// New returns a generator with the default sources, writing to the project location on disk.
+func New(opts Options) *Generator {
+	return &Generator{
+		Options: opts,
+		Sources: DefaultSources(opts),
+		Sink:    NewDirSink(opts.Location),
+	}
+}
+
+// DefaultSources returns the profile source followed by the preset, CI, deploy, release and embedded layout sources.
+// Profiles come first so that their files take precedence over the generated files.
+func DefaultSources(opts Options) []Source {
+	return []Source{
+		NewProfileSource(opts.ProfilesDir),
+		NewPresetSource(),
+		NewCISource(),
+		NewDeploySource(),
+		NewReleaseSource(),
+		NewLayoutSource(),
+	}
+}
+
+// Plan collects the entries of all sources. When several sources produce the
+// same path, the first one wins.
+func (g *Generator) Plan() (*Plan, error) {
+	if err := g.Options.Validate(); err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{Options: g.Options}
+	seen := make(map[string]bool)
+
+	for _, src := range g.Sources {
+		entries, err := src.Entries(g.Options)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", src.Name(), err)
+		}
+
+		for _, e := range entries {
+			e.Path = path.Clean(filepath.ToSlash(e.Path))
+			if seen[e.Path] {
+				continue
+			}
+			seen[e.Path] = true
+
+			if e.Source == "" {
+				e.Source = src.Name()
+			}
+			plan.Entries = append(plan.Entries, e)
+		}
+	}
+
+	return plan, nil
+}
+
+// Apply creates the entries of the plan in the sink. Existing files are never
+// overwritten, they are reported as errors and the apply continues. Only the
+// directories present before the apply are skipped, the ones created along
+// with the files written into them are created by the apply.
+func (g *Generator) Apply(plan *Plan) *Result {
+	res := &Result{}
+
+	// the errors of the directories are reported by the apply below
+	existed := map[string]bool{}
+	for _, e := range plan.Entries {
+		if e.Kind == DirEntry {
+			existed[e.Path], _ = g.Sink.Exists(e.Path)
+		}
+	}
+
+	for _, e := range plan.Entries {
+		exists, err := g.Sink.Exists(e.Path)
+		if err != nil {
+			res.Errors = append(res.Errors, err)
+			continue
+		}
+
+		if exists && e.Kind == DirEntry && !existed[e.Path] {
+			res.Created = append(res.Created, e.Path)
+			continue
+		}
+		if exists {
+			res.Skipped = append(res.Skipped, e.Path)
+			if e.Kind != DirEntry {
+				res.Errors = append(res.Errors, errors.New(e.Path+": file already exists"))
+			}
+			continue
+		}
+
+		switch e.Kind {
+		case DirEntry:
+			err = g.Sink.MkdirAll(e.Path, e.Mode)
+		case FileEntry:
+			err = g.Sink.WriteFile(e.Path, e.Data, e.Mode)
+		case SymlinkEntry:
+			err = g.Sink.Symlink(string(e.Data), e.Path)
+		}
+
+		if err != nil {
+			res.Errors = append(res.Errors, err)
+			continue
+		}
+		res.Created = append(res.Created, e.Path)
+	}
+
+	return res
+}
+
+// Generate plans and applies in one step.
+func (g *Generator) Generate() (*Plan, *Result, error) {
+	plan, err := g.Plan()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return plan, g.Apply(plan), nil
+}
@@ -0,0 +1,49 @@
+package scaffold
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dark-shade/go-setup/pkg/vfs"
+)
+
+func TestApply(t *testing.T) {
+	plan := &Plan{Entries: []Entry{
+		{Kind: FileEntry, Path: "test/data/fixture.json", Data: []byte("{}\n"), Mode: 0644},
+		{Kind: DirEntry, Path: "test", Mode: 0755},
+		{Kind: DirEntry, Path: "test/data", Mode: 0755},
+		{Kind: DirEntry, Path: "docs", Mode: 0755},
+		{Kind: FileEntry, Path: "README.md", Data: []byte("# app\n"), Mode: 0644},
+		{Kind: DirEntry, Path: "configs", Mode: 0755},
+	}}
+
+	fsys := vfs.Memory()
+	if err := fsys.MkdirAll("docs", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := vfs.WriteFile(fsys, "README.md", []byte("# mine\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	g := &Generator{Sink: NewFSSink(fsys)}
+	res := g.Apply(plan)
+
+	// test and test/data are created with the fixture, only docs was there before
+	if want := []string{"test/data/fixture.json", "test", "test/data", "configs"}; !reflect.DeepEqual(res.Created, want) {
+		t.Errorf("got created %v, want %v", res.Created, want)
+	}
+	if want := []string{"docs", "README.md"}; !reflect.DeepEqual(res.Skipped, want) {
+		t.Errorf("got skipped %v, want %v", res.Skipped, want)
+	}
+	if len(res.Errors) != 1 || res.Errors[0].Error() != "README.md: file already exists" {
+		t.Errorf("got errors %v, want README.md: file already exists", res.Errors)
+	}
+
+	data, err := vfs.ReadFile(fsys, "README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "# mine\n" {
+		t.Errorf("README.md was overwritten with %q", data)
+	}
+}
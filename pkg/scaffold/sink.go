@@ -0,0 +1,56 @@
+package scaffold
+
+import (
+	"io/fs"
+	"path/filepath"
+
+	"github.com/dark-shade/go-setup/pkg/vfs"
+)
+
+// Sink receives the entries of an applied plan. Paths are slash separated and
+// relative to the root of the sink.
+type Sink interface {
+	// Exists reports whether something is already present at the path.
+	Exists(name string) (bool, error)
+	// MkdirAll creates a directory along with any necessary parents.
+	MkdirAll(name string, perm fs.FileMode) error
+	// WriteFile creates a file with the given content.
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	// Symlink creates name as a symbolic link to target.
+	Symlink(target, name string) error
+}
+
+// FSSink writes the entries to a filesystem.
+type FSSink struct {
+	FS vfs.FS
+}
+
+// NewFSSink returns a sink writing to the root of fsys.
+func NewFSSink(fsys vfs.FS) *FSSink {
+	return &FSSink{FS: fsys}
+}
+
+// NewDirSink returns a sink writing below the root directory on disk.
+func NewDirSink(root string) *FSSink {
+	return NewFSSink(vfs.BasePath(vfs.OS(), root))
+}
+
+// Exists reports whether something is already present at the path.
+func (s *FSSink) Exists(name string) (bool, error) {
+	return vfs.Exists(s.FS, filepath.FromSlash(name))
+}
+
+// MkdirAll creates a directory along with any necessary parents.
+func (s *FSSink) MkdirAll(name string, perm fs.FileMode) error {
+	return s.FS.MkdirAll(filepath.FromSlash(name), perm)
+}
+
+// WriteFile creates a file with the given content, creating its parent directories if needed.
+func (s *FSSink) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return vfs.WriteFile(s.FS, filepath.FromSlash(name), data, perm)
+}
+
+// Symlink creates name as a symbolic link to target.
+func (s *FSSink) Symlink(target, name string) error {
+	return vfs.Symlink(s.FS, target, filepath.FromSlash(name))
+}
@@ -0,0 +1,251 @@
+package scaffold
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/dark-shade/go-setup/pkg/vfs"
+)
+
+// files starting with . or _ below the directories of data are listed, as
+// they are not embedded otherwise
+//
+//go:embed data/* data/deploy/helm/.helmignore data/deploy/helm/templates/_helpers.tpl
+var data embed.FS
+
+const (
+	dirMode  fs.FileMode = 0755
+	fileMode fs.FileMode = 0644
+)
+
+// Source produces the entries of a plan.
+type Source interface {
+	// Name identifies the source in plans and errors.
+	Name() string
+	// Entries returns the entries the source contributes for the given options.
+	Entries(opts Options) ([]Entry, error)
+}
+
+// bareDirs are the directories of the bare-minimum structure.
+var bareDirs = []string{"bin", "configs", "docs", "examples", "pkg", "scripts", "test/data"}
+
+// remainderDirs are the directories of the full structure excluding the bare and ops ones.
+var remainderDirs = []string{
+	"api", "assets", "build", "cmd", "deployments", "githooks",
+	"init", "internal", "third_party", "tools", "web", "website",
+}
+
+// bareFiles maps the embedded data files of the bare-minimum structure to their project path.
+var bareFiles = [][2]string{
+	{".gitignore", ".gitignore"},
+	{"README.md", "README.md"},
+	{"CHANGELOG.md", "CHANGELOG.md"},
+	{"VERSION", "VERSION"},
+}
+
+// opsFiles maps the embedded data files of the operations structure to their project path.
+var opsFiles = [][2]string{
+	{"Jenkinsfile", "Jenkinsfile"},
+}
+
+// layoutSource produces the recommended layout from the embedded data.
+type layoutSource struct {
+	fsys fs.FS
+}
+
+// NewLayoutSource returns the source of the recommended directory structure and files.
+func NewLayoutSource() Source {
+	return &layoutSource{fsys: data}
+}
+
+// Name returns the name of the source.
+func (s *layoutSource) Name() string {
+	return "layout"
+}
+
+// Entries returns the bare-minimum structure, plus the ops and remainder
+// structures when requested by the options.
+func (s *layoutSource) Entries(opts Options) ([]Entry, error) {
+	var entries []Entry
+
+	// bare-minimum structure
+	for _, dir := range bareDirs {
+		entries = append(entries, Entry{Kind: DirEntry, Path: dir, Mode: dirMode})
+	}
+
+	files, err := s.files(bareFiles)
+	if err != nil {
+		return nil, err
+	}
+	entries = append(entries, files...)
+	entries = append(entries, runnerEntry(opts))
+
+	// presets bring their own main packages
+	if opts.Type == "" {
+		files, err := s.files([][2]string{{"main.go", "main.go"}})
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, files...)
+	}
+
+	if opts.License != "" {
+		licenseData, err := fs.ReadFile(s.fsys, path.Join("data", "licenses", opts.License))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, Entry{Kind: FileEntry, Path: "LICENSE", Data: licenseData, Mode: fileMode})
+	}
+
+	entries = append(entries, Entry{Kind: FileEntry, Path: "go.mod", Data: goMod(opts), Mode: fileMode})
+
+	// operations structure
+	if opts.Full || opts.Ops {
+		files, err := s.files(opsFiles)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, files...)
+
+		files, err = dockerEntries(opts)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, files...)
+	}
+
+	// remainder of the full structure
+	if opts.Full {
+		for _, dir := range remainderDirs {
+			entries = append(entries, Entry{Kind: DirEntry, Path: dir, Mode: dirMode})
+		}
+	}
+
+	return entries, nil
+}
+
+func (s *layoutSource) files(mapping [][2]string) ([]Entry, error) {
+	entries := make([]Entry, 0, len(mapping))
+	for _, m := range mapping {
+		fileData, err := fs.ReadFile(s.fsys, path.Join("data", m[0]))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, Entry{Kind: FileEntry, Path: m[1], Data: fileData, Mode: fileMode})
+	}
+	return entries, nil
+}
+
+// goMod returns the content of the go.mod file for the running go version,
+// requiring the modules of the preset.
+func goMod(opts Options) []byte {
+	var b strings.Builder
+	b.WriteString("module " + ModulePath(opts) + "\n\ngo " + GoVersion() + "\n")
+
+	if preset, ok := LookupPreset(opts.Type); ok && len(preset.Requires) > 0 {
+		b.WriteString("\nrequire (\n")
+		for _, req := range preset.Requires {
+			b.WriteString("\t" + strings.Replace(req, "@", " ", 1) + "\n")
+		}
+		b.WriteString(")\n")
+	}
+
+	return []byte(b.String())
+}
+
+// ModulePath returns the module path of the options, falling back to the
+// name of the project location when none was given.
+func ModulePath(opts Options) string {
+	if opts.ModulePath != "" && opts.ModulePath != "." {
+		return opts.ModulePath
+	}
+
+	abs, err := filepath.Abs(opts.Location)
+	if err != nil {
+		return filepath.Base(opts.Location)
+	}
+	return filepath.Base(abs)
+}
+
+// profileSource produces the content of the profiles found in a directory.
+type profileSource struct {
+	fsys vfs.FS
+	dir  string
+}
+
+// NewProfileSource returns the source of the profiles present in dir on disk.
+func NewProfileSource(dir string) Source {
+	return NewProfileSourceFS(vfs.OS(), dir)
+}
+
+// NewProfileSourceFS returns the source of the profiles present in dir of fsys.
+func NewProfileSourceFS(fsys vfs.FS, dir string) Source {
+	return &profileSource{fsys: fsys, dir: dir}
+}
+
+// Name returns the name of the source.
+func (s *profileSource) Name() string {
+	return "profile"
+}
+
+// Entries returns the files and directories of every profile, in the order of the profiles.
+// The default profile is optional, any other missing profile is an error.
+func (s *profileSource) Entries(opts Options) ([]Entry, error) {
+	var entries []Entry
+
+	for _, profile := range opts.Profiles {
+		root := filepath.Join(s.dir, profile)
+
+		if _, err := s.fsys.Stat(root); err != nil {
+			if os.IsNotExist(err) && profile == "default" {
+				continue
+			}
+			return nil, fmt.Errorf("profile %s: %w", profile, err)
+		}
+
+		err := vfs.Walk(s.fsys, root, func(p string, info fs.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			rel, err := filepath.Rel(root, p)
+			if err != nil || rel == "." {
+				return err
+			}
+
+			e := Entry{Path: filepath.ToSlash(rel), Mode: info.Mode().Perm(), Source: "profile:" + profile}
+
+			switch {
+			case info.IsDir():
+				e.Kind = DirEntry
+			case info.Mode()&os.ModeSymlink != 0:
+				link, err := vfs.Readlink(s.fsys, p)
+				if err != nil {
+					return err
+				}
+				e.Kind = SymlinkEntry
+				e.Data = []byte(link)
+			default:
+				fileData, err := vfs.ReadFile(s.fsys, p)
+				if err != nil {
+					return err
+				}
+				e.Kind = FileEntry
+				e.Data = fileData
+			}
+
+			entries = append(entries, e)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,176 @@
+package scaffold
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/dark-shade/go-setup/pkg/diff"
+	"github.com/dark-shade/go-setup/pkg/vfs"
+)
+
+// DriftKind is how a path of a project diverges from what init generates.
+type DriftKind string
+
+const (
+	// DriftMissing is a generated directory or file missing from the project.
+	DriftMissing DriftKind = "missing"
+	// DriftModified is a generated file whose content differs from what init generates.
+	DriftModified DriftKind = "modified"
+	// DriftOutdated is a generated file not modified by the user, whose template
+	// or profile has newer content since the project was generated.
+	DriftOutdated DriftKind = "outdated"
+	// DriftExtra is a file of the project which is not generated.
+	DriftExtra DriftKind = "extra"
+)
+
+// Drift is a path of a project diverging from what init generates.
+type Drift struct {
+	Kind DriftKind
+	Path string
+	// Entry is the kind of the generated entry, for missing paths.
+	Entry EntryKind
+	// Source is the source generating the path, e.g. layout or profile:default.
+	Source string
+	// Diff is the unified diff from the generated content to the current
+	// content of modified files, and from the recorded to the newer content of
+	// outdated files.
+	Diff string
+}
+
+// ignoredDirs are not inspected for extra files.
+var ignoredDirs = map[string]bool{".git": true, ManifestDir: true, "vendor": true}
+
+// Status compares the project at the root of fsys to the plan of gen, which
+// is rendered in memory. The manifest, when not nil, tells files modified by
+// the user apart from files whose template or profile changed since the
+// generation, and restricts the compared files to those it records. With
+// untracked, the files not recorded by the manifest are compared too, and the
+// files of the project which are not generated are reported as extra.
+// The drifts are sorted by kind and path.
+func Status(fsys vfs.FS, gen *Generator, m *Manifest, untracked bool) ([]Drift, error) {
+	plan, err := gen.Plan()
+	if err != nil {
+		return nil, err
+	}
+
+	var drifts []Drift
+	for _, e := range plan.Entries {
+		if !untracked && !tracked(m, e) {
+			continue
+		}
+
+		d, err := entryDrift(fsys, m, e)
+		if err != nil {
+			return nil, err
+		}
+		if d != nil {
+			drifts = append(drifts, *d)
+		}
+	}
+
+	if untracked {
+		extra, err := extraFiles(fsys, plan)
+		if err != nil {
+			return nil, err
+		}
+		drifts = append(drifts, extra...)
+	}
+
+	order := map[DriftKind]int{DriftMissing: 0, DriftModified: 1, DriftOutdated: 2, DriftExtra: 3}
+	sort.SliceStable(drifts, func(i, j int) bool {
+		if drifts[i].Kind != drifts[j].Kind {
+			return order[drifts[i].Kind] < order[drifts[j].Kind]
+		}
+		return drifts[i].Path < drifts[j].Path
+	})
+
+	return drifts, nil
+}
+
+// tracked reports whether the entry is compared by default: every directory,
+// and the files recorded by the manifest, or every file without a manifest.
+// Files which existed when the project was generated are not recorded, they
+// belong to the user.
+func tracked(m *Manifest, e Entry) bool {
+	if m == nil || e.Kind == DirEntry {
+		return true
+	}
+	_, ok := m.Lookup(e.Path)
+	return ok
+}
+
+// entryDrift returns how the project diverges from the entry, nil when it does not.
+func entryDrift(fsys vfs.FS, m *Manifest, e Entry) (*Drift, error) {
+	name := filepath.FromSlash(e.Path)
+
+	exists, err := vfs.Exists(fsys, name)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if !exists {
+		return &Drift{Kind: DriftMissing, Path: e.Path, Entry: e.Kind, Source: e.Source}, nil
+	}
+
+	// go.mod is owned by the go command once created
+	if e.Kind != FileEntry || unmanaged[e.Path] {
+		return nil, nil
+	}
+
+	current, err := vfs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(current, e.Data) {
+		return nil, nil
+	}
+
+	if m != nil {
+		if _, ok := m.Lookup(e.Path); ok {
+			base, err := ReadBase(fsys, e.Path)
+			if err != nil && !os.IsNotExist(err) {
+				return nil, err
+			}
+			if err == nil && bytes.Equal(current, base) {
+				return &Drift{Kind: DriftOutdated, Path: e.Path, Source: e.Source, Diff: unifiedDiff(e.Path, base, e.Data)}, nil
+			}
+		}
+	}
+
+	return &Drift{Kind: DriftModified, Path: e.Path, Source: e.Source, Diff: unifiedDiff(e.Path, e.Data, current)}, nil
+}
+
+// unifiedDiff returns the unified diff of the two contents of the path.
+func unifiedDiff(name string, a, b []byte) string {
+	return diff.Unified("a/"+name, "b/"+name, diff.Lines(string(a)), diff.Lines(string(b)), diff.DefaultContext)
+}
+
+// extraFiles returns the files of fsys which are not part of the plan.
+func extraFiles(fsys vfs.FS, plan *Plan) ([]Drift, error) {
+	var drifts []Drift
+
+	err := vfs.Walk(fsys, ".", func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel := filepath.ToSlash(p)
+		if info.IsDir() {
+			if ignoredDirs[rel] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if _, ok := plan.Lookup(rel); !ok {
+			drifts = append(drifts, Drift{Kind: DriftExtra, Path: rel})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return drifts, nil
+}
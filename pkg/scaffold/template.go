@@ -0,0 +1,181 @@
+package scaffold
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/dark-shade/go-setup/pkg/project"
+)
+
+// templateExt is the extension of the embedded files rendered with text/template.
+// Files without it are copied as they are.
+const templateExt = ".tmpl"
+
+// TemplateData is the data available to the embedded templates.
+type TemplateData struct {
+	// ModulePath is the module path of the project, e.g. github.com/jane/my-app.
+	ModulePath string
+	// Name is the last element of the module path, e.g. my-app.
+	Name string
+	// Package is the name usable as a go package, e.g. myapp.
+	Package string
+	// Service is the name usable as an exported identifier, e.g. MyApp.
+	Service string
+	// Env is the prefix of the environment variables, e.g. MYAPP.
+	Env string
+	// GoVersion is the go version written to go.mod, e.g. 1.17.
+	GoVersion string
+	Author    string
+	License   string
+	Type      string
+	Year      int
+	// Component is the component being added by go-setup add, if any.
+	Component ComponentData
+	// Command is the cobra command being added by go-setup add command, if any.
+	Command CommandData
+	// Deploy are the settings of the deployment manifests, if any.
+	Deploy DeployData
+}
+
+// NewTemplateData returns the template data for the options.
+func NewTemplateData(opts Options) TemplateData {
+	modPath := ModulePath(opts)
+	name := path.Base(modPath)
+
+	return TemplateData{
+		ModulePath: modPath,
+		Name:       name,
+		Package:    project.PackageName(name),
+		Service:    exportedName(name),
+		Env:        strings.ToUpper(project.PackageName(name)),
+		GoVersion:  GoVersion(),
+		Author:     opts.Author,
+		License:    opts.License,
+		Type:       opts.Type,
+		Year:       year(),
+	}
+}
+
+// year returns the year written to the license and the other generated files.
+// It is pinned by SOURCE_DATE_EPOCH, see https://reproducible-builds.org/specs/source-date-epoch,
+// so generated projects are reproducible, and is the current year otherwise.
+func year() int {
+	if epoch, err := strconv.ParseInt(os.Getenv("SOURCE_DATE_EPOCH"), 10, 64); err == nil {
+		return time.Unix(epoch, 0).UTC().Year()
+	}
+	return time.Now().Year()
+}
+
+// GoVersion returns the major.minor version of the running go toolchain.
+func GoVersion() string {
+	return regexp.MustCompile(`\d.\d+`).FindString(runtime.Version())
+}
+
+// exportedName returns name in camel case, splitting words on every character
+// that is neither a letter nor a digit.
+func exportedName(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var b strings.Builder
+	for _, w := range words {
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+
+	exported := b.String()
+	if exported == "" || !unicode.IsLetter(rune(exported[0])) {
+		exported = "Service" + exported
+	}
+	return exported
+}
+
+// renderPath replaces the {name}, {package}, {component}, {component_package}
+// and {command_file} placeholders of a template path and strips its template extension.
+func renderPath(p string, d TemplateData) string {
+	p = strings.TrimSuffix(p, templateExt)
+	p = strings.ReplaceAll(p, "{name}", d.Name)
+	p = strings.ReplaceAll(p, "{package}", d.Package)
+	p = strings.ReplaceAll(p, "{component}", d.Component.Name)
+	p = strings.ReplaceAll(p, "{component_package}", d.Component.Package)
+	p = strings.ReplaceAll(p, "{command_file}", d.Command.File)
+	return p
+}
+
+// render executes the template text with d, go files are gofmt-ed afterwards.
+func render(name string, text []byte, d TemplateData) ([]byte, error) {
+	return renderDelims(name, text, d, "{{", "}}")
+}
+
+// renderDelims is render with the action delimiters left and right, for
+// templates of files whose own syntax uses {{ }}, e.g. helm charts.
+func renderDelims(name string, text []byte, d TemplateData, left, right string) ([]byte, error) {
+	tmpl, err := template.New(name).Delims(left, right).Option("missingkey=error").Parse(string(text))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, d); err != nil {
+		return nil, err
+	}
+
+	if strings.HasSuffix(strings.TrimSuffix(name, templateExt), ".go") {
+		formatted, err := format.Source(buf.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		return formatted, nil
+	}
+
+	return buf.Bytes(), nil
+}
+
+// templateEntries returns the entries of every file below dir of fsys. Files
+// with the template extension are rendered, the others are copied as they are.
+func templateEntries(fsys fs.FS, dir string, d TemplateData) ([]Entry, error) {
+	return templateEntriesDelims(fsys, dir, d, "{{", "}}")
+}
+
+// templateEntriesDelims is templateEntries with the action delimiters left and right.
+func templateEntriesDelims(fsys fs.FS, dir string, d TemplateData, left, right string) ([]Entry, error) {
+	var entries []Entry
+
+	err := fs.WalkDir(fsys, dir, func(p string, de fs.DirEntry, err error) error {
+		if err != nil || de.IsDir() {
+			return err
+		}
+
+		content, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+
+		if strings.HasSuffix(p, templateExt) {
+			content, err = renderDelims(p, content, d, left, right)
+			if err != nil {
+				return err
+			}
+		}
+
+		rel := strings.TrimPrefix(p, dir+"/")
+		entries = append(entries, Entry{Kind: FileEntry, Path: renderPath(rel, d), Data: content, Mode: fileMode})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,28 @@
+package scaffold
+
+import (
+	"testing"
+	"time"
+)
+
+func TestYear(t *testing.T) {
+	tests := []struct {
+		name  string
+		epoch string
+		want  int
+	}{
+		{"pinned", "946684800", 2000},
+		{"pinned end of year", "1640995199", 2021},
+		{"unset", "", time.Now().Year()},
+		{"invalid", "yesterday", time.Now().Year()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SOURCE_DATE_EPOCH", tt.epoch)
+			if got := year(); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package scaffold
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+
+	"github.com/dark-shade/go-setup/pkg/diff"
+	"github.com/dark-shade/go-setup/pkg/vfs"
+)
+
+// UpgradeStatus is what an upgrade did to a path.
+type UpgradeStatus string
+
+const (
+	// UpgradeUnchanged means the template did not change, or the file already has its new content.
+	UpgradeUnchanged UpgradeStatus = "unchanged"
+	// UpgradeAdded means the path is new in the templates and was created.
+	UpgradeAdded UpgradeStatus = "added"
+	// UpgradeUpdated means the file was not modified by the user and got the new template content.
+	UpgradeUpdated UpgradeStatus = "updated"
+	// UpgradeMerged means the template changes were merged with the user modifications.
+	UpgradeMerged UpgradeStatus = "merged"
+	// UpgradeConflict means the template changes conflict with the user modifications.
+	UpgradeConflict UpgradeStatus = "conflict"
+	// UpgradeSkipped means the file was left untouched, e.g. it was deleted or is not managed by go-setup.
+	UpgradeSkipped UpgradeStatus = "skipped"
+	// UpgradeRemoved means the file is no longer part of the templates, it is kept but no longer managed.
+	UpgradeRemoved UpgradeStatus = "removed"
+)
+
+// UpgradeChange is the outcome of an upgrade for a path.
+type UpgradeChange struct {
+	Path   string
+	Status UpgradeStatus
+	// Detail explains skipped paths and conflicts.
+	Detail string
+}
+
+// UpgradeOptions change how an upgrade handles conflicts.
+type UpgradeOptions struct {
+	// Reject keeps the current content of conflicting files and writes the
+	// template changes to a <path>.rej file, instead of writing conflict markers.
+	Reject bool
+}
+
+// conflictLabels name the sides of the conflict markers written by upgrades.
+var conflictLabels = diff.Labels{Ours: "current", Theirs: "template"}
+
+// Upgrade re-applies the templates of gen to the project at the root of fsys.
+//
+// For every file recorded in the manifest, the content it was generated with
+// is the base of a three-way merge between the user's current file and the
+// newly rendered template. The manifest and base contents are updated
+// afterwards, so the next upgrade starts from the new templates.
+func Upgrade(fsys vfs.FS, gen *Generator, m *Manifest, uo UpgradeOptions) ([]UpgradeChange, error) {
+	plan, err := gen.Plan()
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []UpgradeChange
+	for _, e := range plan.Entries {
+		change, err := upgradeEntry(fsys, m, e, uo)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+
+	// files recorded but no longer generated are left to the user
+	for _, f := range append([]ManifestFile(nil), m.Files...) {
+		if _, ok := plan.Lookup(f.Path); !ok {
+			m.Remove(f.Path)
+			changes = append(changes, UpgradeChange{Path: f.Path, Status: UpgradeRemoved, Detail: "no longer part of the templates, kept as is"})
+		}
+	}
+
+	m.Options = gen.Options
+	if err := WriteManifest(NewFSSink(fsys), m, plan); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// upgradeEntry upgrades a single entry of the new plan.
+func upgradeEntry(fsys vfs.FS, m *Manifest, e Entry, uo UpgradeOptions) (UpgradeChange, error) {
+	change := UpgradeChange{Path: e.Path, Status: UpgradeUnchanged}
+	name := filepath.FromSlash(e.Path)
+	sink := NewFSSink(fsys)
+
+	exists, err := vfs.Exists(fsys, name)
+	if err != nil {
+		return change, err
+	}
+
+	recorded, managed := m.Lookup(e.Path)
+
+	if !exists {
+		if managed {
+			change.Status, change.Detail = UpgradeSkipped, "deleted from the project"
+			return change, nil
+		}
+
+		switch e.Kind {
+		case DirEntry:
+			err = sink.MkdirAll(e.Path, e.Mode)
+		case FileEntry:
+			err = sink.WriteFile(e.Path, e.Data, e.Mode)
+		case SymlinkEntry:
+			err = sink.Symlink(string(e.Data), e.Path)
+		}
+		if err != nil {
+			return change, err
+		}
+
+		if e.Kind == FileEntry && !unmanaged[e.Path] {
+			m.Set(ManifestFile{Path: e.Path, Source: e.Source, SHA256: checksum(e.Data)})
+		}
+		change.Status = UpgradeAdded
+		return change, nil
+	}
+
+	// files owned by other tools once created are left to them
+	if e.Kind != FileEntry || unmanaged[e.Path] {
+		return change, nil
+	}
+
+	current, err := vfs.ReadFile(fsys, name)
+	if err != nil {
+		return change, err
+	}
+
+	if !managed {
+		if !bytes.Equal(current, e.Data) {
+			change.Status, change.Detail = UpgradeSkipped, "not generated by go-setup"
+		}
+		return change, nil
+	}
+
+	base, err := ReadBase(fsys, e.Path)
+	if err != nil {
+		return change, err
+	}
+
+	recorded.Source = e.Source
+	recorded.SHA256 = checksum(e.Data)
+	m.Set(recorded)
+
+	switch {
+	case bytes.Equal(current, e.Data), bytes.Equal(base, e.Data):
+		// nothing new for the file, user modifications are kept
+		return change, nil
+	case bytes.Equal(current, base):
+		change.Status = UpgradeUpdated
+		return change, vfs.WriteFile(fsys, name, e.Data, e.Mode)
+	}
+
+	merged, conflicts := diff.Merge(diff.Lines(string(base)), diff.Lines(string(current)), diff.Lines(string(e.Data)), conflictLabels)
+	if conflicts == 0 {
+		change.Status = UpgradeMerged
+		return change, vfs.WriteFile(fsys, name, []byte(strings.Join(merged, "")), e.Mode)
+	}
+
+	change.Status = UpgradeConflict
+	if uo.Reject {
+		change.Detail = "template changes written to " + e.Path + ".rej"
+		return change, vfs.WriteFile(fsys, name+".rej", []byte(unifiedDiff(e.Path, base, e.Data)), fileMode)
+	}
+
+	change.Detail = "conflict markers written"
+	return change, vfs.WriteFile(fsys, name, []byte(strings.Join(merged, "")), e.Mode)
+}
@@ -0,0 +1,186 @@
+// Package semver parses, compares and bumps semantic versions, see
+// https://semver.org.
+package semver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Parts are the parts of a version that can be bumped.
+var Parts = []string{"major", "minor", "patch", "prerelease"}
+
+// DefaultPreID is the identifier of the prerelease versions of release versions.
+const DefaultPreID = "rc"
+
+// semverRe matches a semantic version, with an optional v prefix.
+var semverRe = regexp.MustCompile(`^(v?)(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)(?:-([0-9A-Za-z.-]+))?(?:\+([0-9A-Za-z.-]+))?$`)
+
+// trailingNumber matches the number ending a prerelease, e.g. the 2 of rc2 or rc.2.
+var trailingNumber = regexp.MustCompile(`^(.*?)([0-9]+)$`)
+
+// Version is a semantic version, see https://semver.org.
+type Version struct {
+	// Prefix is v when the version is written v1.2.3, empty otherwise.
+	Prefix              string
+	Major, Minor, Patch int
+	// Pre is the prerelease of the version, e.g. rc.1, empty for release versions.
+	Pre string
+	// Build is the build metadata of the version, ignored by the comparisons.
+	Build string
+}
+
+// Parse parses a semantic version, e.g. 1.2.3-rc.1 or v1.2.3.
+func Parse(s string) (Version, error) {
+	m := semverRe.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return Version{}, fmt.Errorf("invalid semantic version: %q", s)
+	}
+
+	v := Version{Prefix: m[1], Pre: m[5], Build: m[6]}
+	v.Major, _ = strconv.Atoi(m[2])
+	v.Minor, _ = strconv.Atoi(m[3])
+	v.Patch, _ = strconv.Atoi(m[4])
+	return v, nil
+}
+
+// String returns the version as it is written in the VERSION file.
+func (v Version) String() string {
+	s := fmt.Sprintf("%s%d.%d.%d", v.Prefix, v.Major, v.Minor, v.Patch)
+	if v.Pre != "" {
+		s += "-" + v.Pre
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// Tag returns the name of the git tag of the version, e.g. v1.2.3.
+func (v Version) Tag() string {
+	v.Prefix = "v"
+	return v.String()
+}
+
+// Bump returns the version following v for the part. The build metadata is
+// dropped. Bumping a prerelease to a release drops the prerelease when the
+// prerelease is one of the part, e.g. a patch of 1.2.3-rc.1 is 1.2.3 and a
+// minor of 1.3.0-rc.1 is 1.3.0. A prerelease of a prerelease increments its
+// trailing number, a prerelease of a release is the first prerelease of the
+// next patch. preID is the identifier of new prereleases, rc by default.
+func (v Version) Bump(part, preID string) (Version, error) {
+	next := v
+	next.Build = ""
+
+	switch part {
+	case "major":
+		next.Pre = ""
+		if v.Pre == "" || v.Minor != 0 || v.Patch != 0 {
+			next.Major, next.Minor, next.Patch = v.Major+1, 0, 0
+		}
+	case "minor":
+		next.Pre = ""
+		if v.Pre == "" || v.Patch != 0 {
+			next.Minor, next.Patch = v.Minor+1, 0
+		}
+	case "patch":
+		next.Pre = ""
+		if v.Pre == "" {
+			next.Patch = v.Patch + 1
+		}
+	case "prerelease":
+		next.Pre = nextPre(v, preID)
+		if v.Pre == "" {
+			next.Patch = v.Patch + 1
+		}
+	default:
+		return Version{}, fmt.Errorf("invalid part: %s. Valid values are %s", part, strings.Join(Parts, ", "))
+	}
+
+	if Compare(next, v) <= 0 {
+		return Version{}, fmt.Errorf("version %s does not follow %s", next, v)
+	}
+	return next, nil
+}
+
+// nextPre returns the prerelease following the one of v, which keeps the
+// identifier of v unless preID is another one.
+func nextPre(v Version, preID string) string {
+	if v.Pre == "" {
+		if preID == "" {
+			preID = DefaultPreID
+		}
+		return preID + ".1"
+	}
+
+	m := trailingNumber.FindStringSubmatch(v.Pre)
+	id := v.Pre
+	if m != nil {
+		id = strings.TrimSuffix(m[1], ".")
+	}
+
+	switch {
+	case preID != "" && preID != id:
+		return preID + ".1"
+	case m == nil:
+		return v.Pre + ".1"
+	}
+	n, _ := strconv.Atoi(m[2])
+	return m[1] + strconv.Itoa(n+1)
+}
+
+// Compare returns -1, 0 or +1 depending on whether a precedes, equals or
+// follows b by the precedence rules of semantic versioning.
+func Compare(a, b Version) int {
+	for _, d := range []int{a.Major - b.Major, a.Minor - b.Minor, a.Patch - b.Patch} {
+		if d != 0 {
+			return sign(d)
+		}
+	}
+
+	// a release follows its prereleases
+	switch {
+	case a.Pre == b.Pre:
+		return 0
+	case a.Pre == "":
+		return 1
+	case b.Pre == "":
+		return -1
+	}
+
+	as, bs := strings.Split(a.Pre, "."), strings.Split(b.Pre, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if c := compareIdentifier(as[i], bs[i]); c != 0 {
+			return c
+		}
+	}
+	return sign(len(as) - len(bs))
+}
+
+// compareIdentifier compares two identifiers of prereleases: numeric ones
+// numerically, and before the alphanumeric ones, compared in ASCII order.
+func compareIdentifier(a, b string) int {
+	an, aErr := strconv.Atoi(a)
+	bn, bErr := strconv.Atoi(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		return sign(an - bn)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func sign(d int) int {
+	switch {
+	case d < 0:
+		return -1
+	case d > 0:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,147 @@
+package semver
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Version
+		err  bool
+	}{
+		{in: "1.2.3", want: Version{Major: 1, Minor: 2, Patch: 3}},
+		{in: "v0.1.0", want: Version{Prefix: "v", Minor: 1}},
+		{in: " 1.2.3\n", want: Version{Major: 1, Minor: 2, Patch: 3}},
+		{in: "1.2.3-rc.1", want: Version{Major: 1, Minor: 2, Patch: 3, Pre: "rc.1"}},
+		{in: "1.2.3-beta+exp.sha.5114f85", want: Version{Major: 1, Minor: 2, Patch: 3, Pre: "beta", Build: "exp.sha.5114f85"}},
+		{in: "1.2", err: true},
+		{in: "01.2.3", err: true},
+		{in: "V1.2.3", err: true},
+		{in: "1.2.3-", err: true},
+		{in: "", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			if tt.err {
+				if err == nil {
+					t.Fatalf("got %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	for _, s := range []string{"1.2.3", "v1.2.3", "1.2.3-rc.1", "1.2.3-rc.1+build.7", "1.2.3+build"} {
+		v, err := Parse(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := v.String(); got != s {
+			t.Errorf("got %s, want %s", got, s)
+		}
+	}
+
+	v, _ := Parse("1.2.3-rc.1")
+	if got := v.Tag(); got != "v1.2.3-rc.1" {
+		t.Errorf("got tag %s, want v1.2.3-rc.1", got)
+	}
+}
+
+func TestBump(t *testing.T) {
+	tests := []struct {
+		version string
+		part    string
+		preID   string
+		want    string
+		err     bool
+	}{
+		{version: "1.2.3", part: "major", want: "2.0.0"},
+		{version: "1.2.3", part: "minor", want: "1.3.0"},
+		{version: "1.2.3", part: "patch", want: "1.2.4"},
+		{version: "v1.2.3", part: "patch", want: "v1.2.4"},
+		{version: "1.2.3+build.1", part: "patch", want: "1.2.4"},
+		{version: "1.2.3", part: "prerelease", want: "1.2.4-rc.1"},
+		{version: "1.2.3", part: "prerelease", preID: "beta", want: "1.2.4-beta.1"},
+		{version: "1.2.4-rc.1", part: "prerelease", want: "1.2.4-rc.2"},
+		{version: "1.2.4-rc1", part: "prerelease", want: "1.2.4-rc2"},
+		// rc10 precedes rc9, alphanumeric identifiers are compared in ASCII order
+		{version: "1.2.4-rc9", part: "prerelease", err: true},
+		{version: "1.2.4-alpha.3", part: "prerelease", preID: "beta", want: "1.2.4-beta.1"},
+		{version: "1.2.4-beta", part: "prerelease", want: "1.2.4-beta.1"},
+		{version: "1.2.3-rc.1", part: "patch", want: "1.2.3"},
+		{version: "1.3.0-rc.1", part: "minor", want: "1.3.0"},
+		{version: "1.2.3-rc.1", part: "minor", want: "1.3.0"},
+		{version: "2.0.0-rc.1", part: "major", want: "2.0.0"},
+		{version: "2.1.0-rc.1", part: "major", want: "3.0.0"},
+		{version: "1.2.3", part: "micro", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version+" "+tt.part+" "+tt.preID, func(t *testing.T) {
+			v, err := Parse(tt.version)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := v.Bump(tt.part, tt.preID)
+			if tt.err {
+				if err == nil {
+					t.Fatalf("got %s, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	// the versions in precedence order, see https://semver.org/#spec-item-11
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.1.0",
+		"2.0.0",
+	}
+
+	for i := range ordered {
+		for j := range ordered {
+			a, _ := Parse(ordered[i])
+			b, _ := Parse(ordered[j])
+
+			want := sign(i - j)
+			if got := Compare(a, b); got != want {
+				t.Errorf("Compare(%s, %s) = %d, want %d", a, b, got, want)
+			}
+		}
+	}
+
+	a, _ := Parse("v1.2.3+build.1")
+	b, _ := Parse("1.2.3+build.2")
+	if got := Compare(a, b); got != 0 {
+		t.Errorf("Compare(%s, %s) = %d, want 0, the prefix and build metadata are ignored", a, b, got)
+	}
+}
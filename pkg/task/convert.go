@@ -0,0 +1,114 @@
+package task
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+
+	"github.com/dark-shade/go-setup/pkg/vfs"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	// makeTarget matches the rules of a Makefile, not the := assignments.
+	makeTarget = regexp.MustCompile(`(?m)^([A-Za-z0-9][A-Za-z0-9_.-]*)\s*:([^=]|$)`)
+	// justRecipe matches the recipes of a justfile, with or without parameters.
+	justRecipe = regexp.MustCompile(`(?m)^@?([A-Za-z_][A-Za-z0-9_-]*)[^:=\n]*:([^=]|$)`)
+)
+
+// Skipped is a task of an existing runner file which is not part of the model.
+type Skipped struct {
+	File string
+	Task string
+}
+
+// ConvertResult is the outcome of a Convert.
+type ConvertResult struct {
+	Runner Runner
+	Config Config
+	// Skipped are the tasks of the runner files of the project that were not
+	// converted, as they are not generated by go-setup.
+	Skipped []Skipped
+}
+
+// Convert writes the file of the runner for the project at the root of fsys,
+// from its detected configuration. The files of the other runners are left in
+// place, their tasks which are not generated are reported as skipped.
+func Convert(fsys vfs.FS, to string) (*ConvertResult, error) {
+	runner, ok := runners[to]
+	if !ok {
+		return nil, fmt.Errorf("invalid runner: %s. Valid values are %v", to, Runners())
+	}
+
+	exists, err := vfs.Exists(fsys, runner.File)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if exists {
+		return nil, fmt.Errorf("%s already exists", runner.File)
+	}
+
+	cfg, err := Detect(fsys)
+	if err != nil {
+		return nil, err
+	}
+	m := New(cfg)
+	res := &ConvertResult{Runner: runner, Config: cfg}
+
+	for _, name := range Runners() {
+		other := runners[name]
+		data, err := vfs.ReadFile(fsys, other.File)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, t := range taskNames(other.Name, data) {
+			if _, ok := m.Lookup(t); !ok {
+				res.Skipped = append(res.Skipped, Skipped{File: other.File, Task: t})
+			}
+		}
+	}
+
+	if err := vfs.WriteFile(fsys, runner.File, runner.Render(m), 0644); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// taskNames returns the sorted names of the tasks of the file of the runner,
+// the ones the runner defines itself excluded.
+func taskNames(runner string, data []byte) []string {
+	var names []string
+
+	switch runner {
+	case "make":
+		for _, m := range makeTarget.FindAllSubmatch(data, -1) {
+			if name := string(m[1]); name != "help" {
+				names = append(names, name)
+			}
+		}
+	case "just":
+		for _, m := range justRecipe.FindAllSubmatch(data, -1) {
+			names = append(names, string(m[1]))
+		}
+	case "task":
+		var f struct {
+			Tasks map[string]interface{} `yaml:"tasks"`
+		}
+		if yaml.Unmarshal(data, &f) != nil {
+			return nil
+		}
+		for name := range f.Tasks {
+			if name != "default" {
+				names = append(names, name)
+			}
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,100 @@
+package task
+
+import (
+	"errors"
+	"go/parser"
+	"go/token"
+	"path"
+	"path/filepath"
+
+	"github.com/dark-shade/go-setup/pkg/lint"
+	"github.com/dark-shade/go-setup/pkg/project"
+	"github.com/dark-shade/go-setup/pkg/vfs"
+)
+
+// Detect returns the configuration of the project at the root of fsys: the
+// main packages in the root and in cmd/, the version variable they declare and
+// whether it has a Dockerfile and a goreleaser configuration.
+func Detect(fsys vfs.FS) (Config, error) {
+	p, err := lint.Load(fsys)
+	if err != nil {
+		return Config{}, err
+	}
+	if p.Module == "" {
+		return Config{}, errors.New("go.mod not found in the project root")
+	}
+
+	cfg := Config{Name: project.Name(p.Module)}
+
+	for _, pkg := range p.SortedPackages() {
+		dir := pkg.Dir
+		if pkg.Name != "main" {
+			continue
+		}
+
+		switch {
+		case dir == ".":
+			cfg.Binaries = append(cfg.Binaries, project.Binary{Name: cfg.Name, Package: "."})
+		case path.Dir(dir) == "cmd":
+			cfg.Binaries = append(cfg.Binaries, project.Binary{Name: path.Base(dir), Package: "./" + dir})
+		default:
+			continue
+		}
+
+		if cfg.VersionVar == "" {
+			cfg.VersionVar = versionVar(p, dir)
+		}
+	}
+
+	for _, f := range p.Files {
+		switch f {
+		case "Dockerfile":
+			cfg.Docker = true
+		case ".goreleaser.yaml", ".goreleaser.yml":
+			cfg.Goreleaser = true
+		}
+	}
+
+	return cfg, nil
+}
+
+// versionVar returns the version variable of the main package in dir: the
+// Version of the internal/version package generated by go-setup, a version
+// variable of the main package itself, or of the cmd package of the module, as
+// generated for cobra applications. It is empty when there is none.
+func versionVar(p *lint.Project, dir string) string {
+	if pkg, ok := p.Packages["internal/version"]; ok && pkg.Name == "version" && declaresVar(p, "internal/version", "Version") {
+		return p.ImportPath("internal/version") + ".Version"
+	}
+	if declaresVar(p, dir, "version") {
+		return "main.version"
+	}
+	if pkg, ok := p.Packages["cmd"]; ok && pkg.Name != "main" && declaresVar(p, "cmd", "version") {
+		return p.ImportPath("cmd") + ".version"
+	}
+	return ""
+}
+
+// declaresVar reports whether the non test files of the package in dir
+// declare the package level variable name.
+func declaresVar(p *lint.Project, dir, name string) bool {
+	for _, f := range p.Packages[dir].Files {
+		if f.Test {
+			continue
+		}
+
+		src, err := vfs.ReadFile(p.FS, filepath.FromSlash(f.Path))
+		if err != nil {
+			continue
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), f.Path, src, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+
+		if project.DeclaresVar(file, name) {
+			return true
+		}
+	}
+	return false
+}
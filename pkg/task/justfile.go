@@ -0,0 +1,69 @@
+package task
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Justfile renders the model as a justfile. The variables are lower cased, as
+// is usual for just, and can be overridden by the environment variables of
+// the model names.
+func Justfile(m Model) []byte {
+	var b strings.Builder
+
+	for _, v := range m.Vars {
+		value := m.justValue(v.Value)
+		if v.Shell {
+			value = "`" + v.Value + "`"
+		}
+		fmt.Fprintf(&b, "%s := env_var_or_default(%q, %s)\n", strings.ToLower(v.Name), v.Name, value)
+	}
+
+	for i, t := range m.Tasks {
+		if i > 0 || len(m.Vars) > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "# %s\n", t.Description)
+
+		recipe := t.Name + ":"
+		if len(t.Deps) > 0 {
+			recipe += " " + strings.Join(t.Deps, " ")
+		}
+		b.WriteString(recipe + "\n")
+		for _, c := range t.Commands {
+			fmt.Fprintf(&b, "    %s\n", m.justExpand(c))
+		}
+	}
+
+	return []byte(b.String())
+}
+
+// justExpand returns s with the variable references in just interpolation
+// syntax, and the other braces escaped.
+func (m Model) justExpand(s string) string {
+	return m.expand(s, func(name string) string {
+		return "{{" + strings.ToLower(name) + "}}"
+	}, func(s string) string {
+		return strings.ReplaceAll(s, "{{", "{{{{")
+	})
+}
+
+// justValue returns s as a just expression, concatenating the quoted text
+// and the referenced variables.
+func (m Model) justValue(s string) string {
+	var parts []string
+	m.expand(s, func(name string) string {
+		parts = append(parts, strings.ToLower(name))
+		return ""
+	}, func(s string) string {
+		if s != "" {
+			parts = append(parts, strconv.Quote(s))
+		}
+		return ""
+	})
+	if len(parts) == 0 {
+		return `""`
+	}
+	return strings.Join(parts, " + ")
+}
@@ -0,0 +1,135 @@
+package task
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dark-shade/go-setup/pkg/vfs"
+)
+
+// The generated targets of a Makefile are enclosed in these markers, so
+// SyncMakefile regenerates them without touching the targets of the users.
+const (
+	BeginMarker = "# go-setup:begin"
+	EndMarker   = "# go-setup:end"
+)
+
+// help lists the targets with their ## comments.
+const help = `help: ## Show the targets
+	@awk 'BEGIN {FS = ":.*## "} /^[a-zA-Z0-9_-]+:.*## / {printf "  %-16s %s\n", $$1, $$2}' $(MAKEFILE_LIST)
+`
+
+// Makefile renders the model as a Makefile with a self-documenting help target.
+func Makefile(m Model) []byte {
+	var b strings.Builder
+	b.WriteString(BeginMarker + "\n")
+	b.WriteString("# The targets up to go-setup:end are generated, go-setup makefile sync\n# regenerates them when binaries are added. Add your own targets below.\n\n")
+
+	for _, v := range m.Vars {
+		value := m.makeExpand(v.Value)
+		if v.Shell {
+			value = "$(shell " + v.Value + ")"
+		}
+		fmt.Fprintf(&b, "%s ?= %s\n", v.Name, value)
+	}
+
+	names := []string{"help"}
+	for _, t := range m.Tasks {
+		names = append(names, t.Name)
+	}
+	fmt.Fprintf(&b, "\n.PHONY: %s\n", strings.Join(names, " "))
+
+	for i, t := range m.Tasks {
+		b.WriteString("\n")
+		target := t.Name + ":"
+		if len(t.Deps) > 0 {
+			target += " " + strings.Join(t.Deps, " ")
+		}
+		fmt.Fprintf(&b, "%s ## %s\n", target, t.Description)
+		for _, c := range t.Commands {
+			fmt.Fprintf(&b, "\t%s\n", m.makeExpand(c))
+		}
+
+		// help follows the default target
+		if i == 0 {
+			b.WriteString("\n" + help)
+		}
+	}
+
+	b.WriteString("\n" + EndMarker + "\n")
+	return []byte(b.String())
+}
+
+// makeExpand returns s with the variable references in make syntax and the
+// other dollar signs escaped.
+func (m Model) makeExpand(s string) string {
+	return m.expand(s, func(name string) string {
+		return "$(" + name + ")"
+	}, func(s string) string {
+		return strings.ReplaceAll(s, "$", "$$")
+	})
+}
+
+// SyncMakefile returns the Makefile with its generated section replaced by the
+// one of the model, the rest of the file is kept. An empty Makefile is
+// replaced by the generated one.
+func SyncMakefile(current []byte, m Model) ([]byte, error) {
+	generated := Makefile(m)
+	if len(bytes.TrimSpace(current)) == 0 {
+		return generated, nil
+	}
+
+	begin := bytes.Index(current, []byte(BeginMarker))
+	end := bytes.Index(current, []byte(EndMarker))
+	if begin < 0 || end < begin {
+		return nil, errors.New("the Makefile has no section generated by go-setup, between the " + BeginMarker + " and " + EndMarker + " lines")
+	}
+
+	end += len(EndMarker)
+	if end < len(current) && current[end] == '\n' {
+		end++
+	}
+
+	var b bytes.Buffer
+	b.Write(current[:begin])
+	b.Write(generated)
+	b.Write(current[end:])
+	return b.Bytes(), nil
+}
+
+// SyncResult is the outcome of a Sync.
+type SyncResult struct {
+	Config Config
+	// Changed reports whether the Makefile was written.
+	Changed bool
+}
+
+// Sync regenerates the generated section of the Makefile of the project at
+// the root of fsys from the detected configuration of the project.
+func Sync(fsys vfs.FS) (*SyncResult, error) {
+	cfg, err := Detect(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := vfs.ReadFile(fsys, "Makefile")
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	synced, err := SyncMakefile(current, New(cfg))
+	if err != nil {
+		return nil, err
+	}
+
+	res := &SyncResult{Config: cfg, Changed: !bytes.Equal(current, synced)}
+	if res.Changed {
+		if err := vfs.WriteFile(fsys, "Makefile", synced, 0644); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,55 @@
+package task
+
+import (
+	"sort"
+)
+
+// Runner is a task runner the model can be rendered for.
+type Runner struct {
+	Name string
+	// File is the path of the file of the runner in the project.
+	File   string
+	Render func(Model) []byte
+	// Command runs a task when followed by its name, on a machine with go.
+	Command string
+	// Install is the shell command installing the runner on a machine with go,
+	// empty when the command needs no installation.
+	Install string
+}
+
+// DefaultRunner is the name of the runner used when none is given.
+const DefaultRunner = "make"
+
+// runners are the supported task runners by name.
+var runners = map[string]Runner{
+	"make": {Name: "make", File: "Makefile", Render: Makefile, Command: "make"},
+	// task is a go program, it is run with go run rather than installed
+	"task": {Name: "task", File: "Taskfile.yml", Render: Taskfile, Command: "go run github.com/go-task/task/v3/cmd/task@latest"},
+	"just": {
+		Name:    "just",
+		File:    "justfile",
+		Render:  Justfile,
+		Command: "$HOME/bin/just",
+		Install: "curl -sSf https://just.systems/install.sh | bash -s -- --to $HOME/bin",
+	},
+}
+
+// Runners returns the names of the supported task runners.
+func Runners() []string {
+	names := make([]string, 0, len(runners))
+	for name := range runners {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupRunner returns the runner with the given name, the default one for an
+// empty name, and whether it exists.
+func LookupRunner(name string) (Runner, bool) {
+	if name == "" {
+		name = DefaultRunner
+	}
+	r, ok := runners[name]
+	return r, ok
+}
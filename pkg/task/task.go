@@ -0,0 +1,208 @@
+// Package task generates the task runner files of projects.
+//
+// The tasks of a project (building each binary, testing, linting and so on)
+// are described once by a Model derived from the project, and rendered to the
+// file of a task runner, e.g. a Makefile.
+package task
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/dark-shade/go-setup/pkg/project"
+)
+
+// Config are the project settings the tasks depend on.
+type Config struct {
+	// Name is the name of the project, used for the docker image.
+	Name string
+	// Binaries are the main packages of the project, none for libraries.
+	Binaries []project.Binary
+	// VersionVar is the variable the version is set to with -ldflags -X, e.g. main.version.
+	VersionVar string
+	// Docker adds the docker task, for projects with a Dockerfile.
+	Docker bool
+	// Goreleaser releases with goreleaser and packages the binaries with
+	// nfpm, for projects with a .goreleaser.yaml.
+	Goreleaser bool
+}
+
+// Var is a variable of the tasks, referenced as $(NAME) in the commands.
+type Var struct {
+	Name string
+	// Value is the default value, or the command printing it when Shell is set.
+	Value string
+	Shell bool
+}
+
+// Task is a named sequence of commands.
+type Task struct {
+	Name        string
+	Description string
+	// Deps are the tasks run before the commands.
+	Deps []string
+	// Commands are run by the shell, $(NAME) references a variable.
+	Commands []string
+}
+
+// Platforms are the GOOS/GOARCH pairs the release task builds for.
+var Platforms = []string{"linux/amd64", "linux/arm64", "darwin/amd64", "darwin/arm64", "windows/amd64"}
+
+// Model is the variables and tasks of a project.
+type Model struct {
+	Vars []Var
+	// Tasks are in the order they are listed, the first one is the default.
+	Tasks []Task
+}
+
+// Lookup returns the task with the given name and whether it exists.
+func (m Model) Lookup(name string) (Task, bool) {
+	for _, t := range m.Tasks {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Task{}, false
+}
+
+// New returns the model of the tasks of the project.
+func New(cfg Config) Model {
+	m := Model{
+		Vars: []Var{
+			{Name: "VERSION", Value: "cat VERSION 2>/dev/null || git describe --tags --always --dirty 2>/dev/null || echo dev", Shell: true},
+		},
+	}
+	if cfg.VersionVar != "" {
+		m.Vars = append(m.Vars, Var{Name: "LDFLAGS", Value: "-X " + cfg.VersionVar + "=$(VERSION)"})
+	}
+	if cfg.Docker {
+		m.Vars = append(m.Vars, Var{Name: "IMAGE", Value: cfg.Name})
+	}
+
+	m.Tasks = append(m.Tasks,
+		Task{Name: "all", Description: "Format, vet and build", Deps: []string{"fmt", "vet", "build"}},
+		Task{Name: "fmt", Description: "Run go fmt against code", Commands: []string{"go fmt ./..."}},
+		Task{Name: "vet", Description: "Run go vet against code", Commands: []string{"go vet ./..."}},
+	)
+
+	build := Task{Name: "build", Description: "Build the binaries to bin/"}
+	var builds []Task
+	for _, b := range cfg.Binaries {
+		name := "build-" + b.Name
+		build.Deps = append(build.Deps, name)
+
+		cmd := goBuild(cfg) + " -o bin/" + b.Name + " " + b.Package
+		builds = append(builds, Task{Name: name, Description: "Build bin/" + b.Name, Commands: []string{cmd}})
+	}
+	if len(cfg.Binaries) == 0 {
+		build.Description = "Build the packages"
+		build.Commands = []string{"go build ./..."}
+	}
+	m.Tasks = append(m.Tasks, build)
+	m.Tasks = append(m.Tasks, builds...)
+
+	m.Tasks = append(m.Tasks,
+		Task{Name: "test", Description: "Run the tests", Commands: []string{"go test ./..."}},
+		Task{Name: "race", Description: "Run the tests with the race detector", Commands: []string{"go test -race ./..."}},
+		Task{Name: "cover", Description: "Run the tests with coverage, the profile is written to coverage.out", Commands: []string{
+			"go test -coverprofile=coverage.out ./...",
+			"go tool cover -func=coverage.out",
+		}},
+		Task{Name: "bench", Description: "Run the benchmarks", Commands: []string{"go test -run=^$ -bench=. -benchmem ./..."}},
+		Task{Name: "lint", Description: "Run golangci-lint against code", Commands: []string{
+			"go run github.com/golangci/golangci-lint/v2/cmd/golangci-lint@latest run ./...",
+		}},
+		Task{Name: "generate", Description: "Run go generate", Commands: []string{"go generate ./..."}},
+		Task{Name: "tidy", Description: "Run go mod tidy", Commands: []string{"go mod tidy"}},
+		Task{Name: "clean", Description: "Remove the build outputs", Commands: []string{"rm -rf bin/ dist/ coverage.out"}},
+	)
+
+	switch {
+	case cfg.Goreleaser:
+		m.Tasks = append(m.Tasks, goreleaserTasks()...)
+	case len(cfg.Binaries) > 0:
+		var pkgs []string
+		for _, b := range cfg.Binaries {
+			pkgs = append(pkgs, b.Package)
+		}
+
+		cmd := goBuild(cfg) + " -o dist/${platform%/*}_${platform#*/}/ " + strings.Join(pkgs, " ")
+		m.Tasks = append(m.Tasks, Task{Name: "release", Description: "Build the binaries of every release platform to dist/", Commands: []string{
+			"for platform in " + strings.Join(Platforms, " ") + "; do GOOS=${platform%/*} GOARCH=${platform#*/} " + cmd + " || exit 1; done",
+		}})
+	}
+
+	if cfg.Docker {
+		m.Tasks = append(m.Tasks, Task{Name: "docker", Description: "Build the docker image", Commands: []string{
+			dockerBuild(cfg),
+		}})
+	}
+
+	return m
+}
+
+// goreleaserTasks returns the tasks releasing with goreleaser and packaging
+// the binaries of bin/ with the nfpm configuration of build/package.
+func goreleaserTasks() []Task {
+	const goreleaser = "go run github.com/goreleaser/goreleaser/v2@latest"
+	return []Task{
+		{Name: "release", Description: "Publish the release of the current tag with goreleaser", Commands: []string{
+			goreleaser + " release --clean",
+		}},
+		{Name: "snapshot", Description: "Build the release of the current commit to dist/ without publishing it", Commands: []string{
+			goreleaser + " release --snapshot --clean",
+		}},
+		{Name: "package", Description: "Package the linux binaries of bin/ as deb and rpm to dist/", Deps: []string{"build"}, Commands: []string{
+			"mkdir -p dist",
+			"for packager in deb rpm; do VERSION=$(VERSION) GOARCH=$(go env GOARCH) go run github.com/goreleaser/nfpm/v2/cmd/nfpm@latest pkg --config build/package/nfpm.yaml --packager $packager --target dist/ || exit 1; done",
+		}},
+	}
+}
+
+// dockerBuild returns the docker build command of the image, passing the
+// version to the Dockerfile when the binaries have a version variable.
+func dockerBuild(cfg Config) string {
+	if cfg.VersionVar == "" {
+		return "docker build -t $(IMAGE):$(VERSION) ."
+	}
+	return "docker build --build-arg VERSION=$(VERSION) -t $(IMAGE):$(VERSION) ."
+}
+
+// goBuild returns the go build command of the binaries, with the version ldflags if any.
+func goBuild(cfg Config) string {
+	if cfg.VersionVar == "" {
+		return "go build"
+	}
+	return `go build -ldflags "$(LDFLAGS)"`
+}
+
+// varRef matches the $(NAME) references of the commands.
+var varRef = regexp.MustCompile(`\$\(([A-Za-z_][A-Za-z0-9_]*)\)`)
+
+// expand returns s with the references to the variables of the model
+// replaced by the result of ref, and the text around them by the result of lit.
+func (m Model) expand(s string, ref func(name string) string, lit func(s string) string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range varRef.FindAllStringSubmatchIndex(s, -1) {
+		name := s[loc[2]:loc[3]]
+		if !m.isVar(name) {
+			continue
+		}
+		b.WriteString(lit(s[last:loc[0]]))
+		b.WriteString(ref(name))
+		last = loc[1]
+	}
+	b.WriteString(lit(s[last:]))
+	return b.String()
+}
+
+// isVar reports whether the model has a variable with the given name.
+func (m Model) isVar(name string) bool {
+	for _, v := range m.Vars {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
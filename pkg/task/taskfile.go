@@ -0,0 +1,56 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Taskfile renders the model as a go-task Taskfile.yml. The dependencies of a
+// task are run as its first commands, so they run in order as with make.
+func Taskfile(m Model) []byte {
+	var b strings.Builder
+	b.WriteString("version: '3'\n")
+
+	if len(m.Vars) > 0 {
+		b.WriteString("\nvars:\n")
+		for _, v := range m.Vars {
+			if v.Shell {
+				fmt.Fprintf(&b, "  %s:\n    sh: %s\n", v.Name, yamlQuote(v.Value))
+				continue
+			}
+			fmt.Fprintf(&b, "  %s: %s\n", v.Name, yamlQuote(m.taskExpand(v.Value)))
+		}
+	}
+
+	b.WriteString("\ntasks:\n")
+	if len(m.Tasks) > 0 {
+		fmt.Fprintf(&b, "  default:\n    cmds:\n      - task: %s\n", m.Tasks[0].Name)
+	}
+
+	for _, t := range m.Tasks {
+		fmt.Fprintf(&b, "\n  %s:\n    desc: %s\n", t.Name, yamlQuote(t.Description))
+		b.WriteString("    cmds:\n")
+		for _, d := range t.Deps {
+			fmt.Fprintf(&b, "      - task: %s\n", d)
+		}
+		for _, c := range t.Commands {
+			fmt.Fprintf(&b, "      - %s\n", yamlQuote(m.taskExpand(c)))
+		}
+	}
+
+	return []byte(b.String())
+}
+
+// taskExpand returns s with the variable references in go-task template syntax.
+func (m Model) taskExpand(s string) string {
+	return m.expand(s, func(name string) string {
+		return "{{." + name + "}}"
+	}, func(s string) string {
+		return s
+	})
+}
+
+// yamlQuote returns s as a single quoted YAML string.
+func yamlQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,86 @@
+package vfs
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// Journal makes changes to a filesystem and records how to undo them, so a
+// refactoring failing halfway leaves the project as it was. The paths are
+// slash separated.
+type Journal struct {
+	fsys  FS
+	undos []func() error
+}
+
+// NewJournal returns a journal of the changes made to fsys.
+func NewJournal(fsys FS) *Journal {
+	return &Journal{fsys: fsys}
+}
+
+// MkdirAll creates the directory dir along with any necessary parents.
+func (j *Journal) MkdirAll(dir string) error {
+	// the missing directories are removed again when undone, deepest first
+	var missing []string
+	for d := dir; d != "." && d != "/"; d = path.Dir(d) {
+		exists, err := Exists(j.fsys, filepath.FromSlash(d))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if exists {
+			break
+		}
+		missing = append(missing, d)
+	}
+
+	if err := j.fsys.MkdirAll(filepath.FromSlash(dir), 0755); err != nil {
+		return err
+	}
+
+	for i := len(missing) - 1; i >= 0; i-- {
+		d := missing[i]
+		j.undos = append(j.undos, func() error { return j.fsys.Remove(filepath.FromSlash(d)) })
+	}
+	return nil
+}
+
+// Rename moves the file or directory from to to.
+func (j *Journal) Rename(from, to string) error {
+	if err := j.fsys.Rename(filepath.FromSlash(from), filepath.FromSlash(to)); err != nil {
+		return err
+	}
+
+	j.undos = append(j.undos, func() error { return j.fsys.Rename(filepath.FromSlash(to), filepath.FromSlash(from)) })
+	return nil
+}
+
+// WriteFile replaces the content of the existing file name.
+func (j *Journal) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	name = filepath.FromSlash(name)
+
+	old, err := ReadFile(j.fsys, name)
+	if err != nil {
+		return err
+	}
+	if err := WriteFile(j.fsys, name, data, perm); err != nil {
+		return err
+	}
+
+	j.undos = append(j.undos, func() error { return WriteFile(j.fsys, name, old, perm) })
+	return nil
+}
+
+// Undo undoes the recorded changes in reverse order. It goes on when one
+// fails, and returns the first error.
+func (j *Journal) Undo() error {
+	var first error
+	for i := len(j.undos) - 1; i >= 0; i-- {
+		if err := j.undos[i](); err != nil && first == nil {
+			first = err
+		}
+	}
+	j.undos = nil
+	return first
+}
@@ -0,0 +1,142 @@
+// Package vfs is the filesystem abstraction used for all scaffolding I/O.
+//
+// It is a thin layer over spf13/afero, providing the OS, in-memory, read-only
+// overlay and base-path filesystems along with the helpers go-setup needs on
+// top of the afero.Fs interface.
+package vfs
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+)
+
+// FS is the filesystem interface all scaffolding I/O goes through.
+type FS = afero.Fs
+
+// ErrSymlinkNotSupported is returned when a filesystem cannot create or read symbolic links.
+var ErrSymlinkNotSupported = errors.New("symlinks are not supported by the filesystem")
+
+// OS returns the filesystem of the operating system.
+func OS() FS {
+	return afero.NewOsFs()
+}
+
+// Memory returns an empty in-memory filesystem.
+func Memory() FS {
+	return afero.NewMemMapFs()
+}
+
+// ReadOnly returns a view of base which refuses every write.
+func ReadOnly(base FS) FS {
+	return afero.NewReadOnlyFs(base)
+}
+
+// Overlay returns a filesystem that reads from base and keeps every write in
+// memory, base itself is never modified.
+func Overlay(base FS) FS {
+	return afero.NewCopyOnWriteFs(afero.NewReadOnlyFs(base), afero.NewMemMapFs())
+}
+
+// BasePath returns a filesystem restricted to dir of base, every path is relative to dir.
+// A relative dir is made absolute against the working directory, and relative
+// symlink targets are kept as they are instead of being resolved against dir.
+func BasePath(base FS, dir string) FS {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	return &basePathFs{BasePathFs: afero.NewBasePathFs(base, dir).(*afero.BasePathFs), source: base}
+}
+
+// basePathFs is an afero.BasePathFs which keeps relative symlink targets relative.
+type basePathFs struct {
+	*afero.BasePathFs
+	source FS
+}
+
+// SymlinkIfPossible creates newname as a symbolic link to oldname.
+func (b *basePathFs) SymlinkIfPossible(oldname, newname string) error {
+	if filepath.IsAbs(oldname) {
+		return b.BasePathFs.SymlinkIfPossible(oldname, newname)
+	}
+
+	realname, err := b.RealPath(newname)
+	if err != nil {
+		return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: err}
+	}
+
+	linker, ok := b.source.(afero.Linker)
+	if !ok {
+		return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: ErrSymlinkNotSupported}
+	}
+	return linker.SymlinkIfPossible(oldname, realname)
+}
+
+// IOFS returns the io/fs view of fsys.
+func IOFS(fsys FS) fs.FS {
+	return afero.NewIOFS(fsys)
+}
+
+// Exists returns whether the given file, directory or symlink exists, without following symlinks.
+func Exists(fsys FS, name string) (bool, error) {
+	_, err := Lstat(fsys, name)
+	if err == nil {
+		return true, nil
+	}
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+// Lstat returns the file info of name without following symlinks when the filesystem supports it.
+func Lstat(fsys FS, name string) (fs.FileInfo, error) {
+	if l, ok := fsys.(afero.Lstater); ok {
+		info, _, err := l.LstatIfPossible(name)
+		return info, err
+	}
+	return fsys.Stat(name)
+}
+
+// ReadFile reads the whole content of a file.
+func ReadFile(fsys FS, name string) ([]byte, error) {
+	return afero.ReadFile(fsys, name)
+}
+
+// WriteFile writes data to a file, creating its parent directories if needed.
+func WriteFile(fsys FS, name string, data []byte, perm fs.FileMode) error {
+	if err := fsys.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+	return afero.WriteFile(fsys, name, data, perm)
+}
+
+// Symlink creates name as a symbolic link to target, creating its parent directories if needed.
+func Symlink(fsys FS, target, name string) error {
+	l, ok := fsys.(afero.Linker)
+	if !ok {
+		return &os.LinkError{Op: "symlink", Old: target, New: name, Err: ErrSymlinkNotSupported}
+	}
+
+	if err := fsys.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+	return l.SymlinkIfPossible(target, name)
+}
+
+// Readlink returns the target of a symbolic link.
+func Readlink(fsys FS, name string) (string, error) {
+	r, ok := fsys.(afero.LinkReader)
+	if !ok {
+		return "", &os.PathError{Op: "readlink", Path: name, Err: ErrSymlinkNotSupported}
+	}
+	return r.ReadlinkIfPossible(name)
+}
+
+// Walk walks the file tree rooted at root in lexical order, calling fn for each file or directory.
+func Walk(fsys FS, root string, fn filepath.WalkFunc) error {
+	return afero.Walk(fsys, root, fn)
+}